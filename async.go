@@ -2,7 +2,7 @@ package jac
 
 import (
 	"context"
-	"sync"
+	"errors"
 	"time"
 )
 
@@ -12,74 +12,125 @@ type AsyncRequest interface {
 	OnReady() Request
 }
 
+// AsyncResponse is the result of an AsyncRequest.
 type AsyncResponse struct {
 	Response *Response
 	Err      error
+
+	// Request is the AsyncRequest the result belongs to.
+	Request AsyncRequest
+
+	// Index is the position of the Request among the requests
+	// passed to DoAsync.
+	Index int
+
+	// Polls is the amount of polls made for the Request.
+	Polls int
+
+	// Elapsed is the total duration from the first poll
+	// until the result was ready.
+	Elapsed time.Duration
 }
 
-// DoAsync repeats a given request until the provided Until function returns true.
+// DoAsync polls the given requests until they are ready and sends the
+// Responses of their OnReady requests on the returned channel.
+//
+// Polling is governed by the Client's PollPolicy. Requests that are not
+// ready within its limits result in a PollTimeoutError.
 func (c *Client) DoAsync(ctx context.Context, asyncs ...AsyncRequest) chan *AsyncResponse {
+	c.once.Do(c.init)
 	ch := make(chan *AsyncResponse)
-	go c.doAsyncs(ch, ctx, asyncs)
+	go c.doAsyncs(ch, ctx, asyncs, func(ctx context.Context, _ int, request AsyncRequest, p *poller) *AsyncResponse {
+		return c.poll(ctx, request, p)
+	})
 
 	return ch
 }
 
-func (c *Client) doAsyncs(ch chan *AsyncResponse, ctx context.Context, asyncs []AsyncRequest) {
-	var wg sync.WaitGroup
-	for _, async := range asyncs {
-		a := async
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			c.doAsync(ctx, a, ch)
-		}()
+// DoAsyncCollect is like DoAsync but waits for every request
+// and returns the results in the order of the requests.
+func (c *Client) DoAsyncCollect(ctx context.Context, asyncs ...AsyncRequest) []*AsyncResponse {
+	results := make([]*AsyncResponse, len(asyncs))
+	for res := range c.DoAsync(ctx, asyncs...) {
+		results[res.Index] = res
 	}
-	wg.Wait()
+
+	return results
+}
+
+// asyncRunner waits for the AsyncRequest at the index to be ready and returns its result.
+type asyncRunner func(ctx context.Context, index int, request AsyncRequest, p *poller) *AsyncResponse
+
+func (c *Client) doAsyncs(ch chan *AsyncResponse, ctx context.Context, asyncs []AsyncRequest, run asyncRunner) {
+	_ = forEach(ctx, len(asyncs), c.Poll.MaxPollers, false, func(ctx context.Context, i int) error {
+		ch <- c.doAsync(ctx, i, asyncs[i], run)
+		return nil
+	})
 	close(ch)
 }
 
-func (c *Client) doAsync(ctx context.Context, request AsyncRequest, ch chan *AsyncResponse) {
-	var attempt int
-	for {
-		if attempt > 5 {
-			attempt = 0
-		}
+// doAsync runs the request and annotates its result with
+// the request, its index and the polling statistics.
+//
+// The job of a ResumableRequest is removed from the JobStore once
+// the request has finished.
+func (c *Client) doAsync(ctx context.Context, index int, request AsyncRequest, run asyncRunner) *AsyncResponse {
+	p := newPoller(c.Poll)
+	p.index = index
+	p.jobs = c.Jobs
+	res := run(ctx, index, request, p)
+	c.finishJob(ctx, p, res)
+	res.Request = request
+	res.Index = index
+	res.Polls = p.polls
+	res.Elapsed = time.Since(p.start)
+
+	return res
+}
 
+func (c *Client) poll(ctx context.Context, request AsyncRequest, p *poller) *AsyncResponse {
+	for {
 		if cacheReq, ok := isAsyncReadyCached(request); ok {
-			response := c.Cache.Get(cacheReq.CacheKey())
+			response := c.cacheGet(ctx, cacheReq.CacheKey())
 			if response != nil {
-				ch <- &AsyncResponse{Response: response}
-				return
+				p.finished = true
+				return &AsyncResponse{Response: response}
 			}
 		}
 
 		response, err := c.Do(ctx, request)
+		p.polls++
 		if err != nil {
-			ch <- &AsyncResponse{Err: err}
-			return
+			return &AsyncResponse{Err: err}
 		}
+		p.reportProgress(request, response)
 
 		ok, err := request.IsReady(response)
 		if err != nil {
-			ch <- &AsyncResponse{Err: err}
-			return
+			p.finished = true
+			return &AsyncResponse{Err: err}
 		}
 
 		if ok {
-			c.doAsyncReady(ctx, request.OnReady(), ch)
-			return
+			p.finished = true
+			return c.doAsyncReady(ctx, request.OnReady())
+		}
+
+		if err := c.recordJob(ctx, request, p); err != nil {
+			return &AsyncResponse{Err: err}
 		}
-		attempt += 1
 
-		time.Sleep(c.Retry.Backoff(attempt))
+		if err := p.next(ctx, 0); err != nil {
+			var timeout *PollTimeoutError
+			p.finished = errors.As(err, &timeout)
+			return &AsyncResponse{Err: err}
+		}
 	}
 }
 
-func (c *Client) doAsyncReady(ctx context.Context, request Request, ch chan *AsyncResponse) {
+func (c *Client) doAsyncReady(ctx context.Context, request Request) *AsyncResponse {
 	res, err := c.Do(ctx, request)
-	ch <- &AsyncResponse{Response: res, Err: err}
-	return
+	return &AsyncResponse{Response: res, Err: err}
 }
 
 func isAsyncReadyCached(req AsyncRequest) (CacheRequest, bool) {
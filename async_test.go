@@ -101,6 +101,7 @@ func TestClient_DoAsync(t *testing.T) {
 				BaseURL: tt.fields.BaseURL,
 				hc:      http.DefaultClient,
 				Retry:   retry,
+				Poll:    &PollPolicy{Interval: time.Millisecond},
 			}
 
 			var got chan *AsyncResponse
@@ -212,3 +213,44 @@ type asyncRequestResponse struct {
 	ID      string
 	Success bool
 }
+
+func TestClient_DoAsyncCollect(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	registerAsyncHandler()
+
+	c := &Client{
+		BaseURL: "https://asynctest.com",
+		hc:      http.DefaultClient,
+		Retry:   &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 3},
+		Poll:    &PollPolicy{Interval: time.Millisecond},
+	}
+	asyncs := []AsyncRequest{
+		&asynRequest{id: "abc"},
+		&asynRequest{id: "fail", fail: true},
+		&asynRequest{id: "123"},
+	}
+
+	got := c.DoAsyncCollect(context.Background(), asyncs...)
+	if len(got) != len(asyncs) {
+		t.Fatalf("Client.DoAsyncCollect() len = %d, want %d", len(got), len(asyncs))
+	}
+	for i, res := range got {
+		if res.Index != i || res.Request != asyncs[i] {
+			t.Errorf("Client.DoAsyncCollect()[%d] index = %d, request = %v", i, res.Index, res.Request)
+		}
+	}
+	if got[1].Err == nil {
+		t.Errorf("Client.DoAsyncCollect()[1] error = nil, want error")
+	}
+	for _, i := range []int{0, 2} {
+		if got[i].Err != nil || got[i].Polls != 2 {
+			t.Errorf("Client.DoAsyncCollect()[%d] = %+v, want success after 2 polls", i, got[i])
+		}
+		var asyncRes asyncRequestResponse
+		_ = json.Unmarshal(got[i].Response.Data, &asyncRes)
+		if asyncRes.ID != got[i].Request.(*asynRequest).id || !asyncRes.Success {
+			t.Errorf("Client.DoAsyncCollect()[%d] response = %+v", i, asyncRes)
+		}
+	}
+}
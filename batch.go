@@ -0,0 +1,128 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchOptions configures the execution of DoAll.
+type BatchOptions struct {
+	// Concurrency is the maximum amount of requests in flight at a time.
+	// Zero means no limit.
+	Concurrency int
+
+	// FailFast cancels the remaining requests after the first failure and
+	// returns its error. Otherwise every request is made and the failures
+	// are returned together in a BatchError.
+	FailFast bool
+}
+
+// BatchError holds the errors of the failed requests of DoAll.
+type BatchError struct {
+	// Errs holds the error of every request in the order of the requests.
+	// The errors of successful requests are nil.
+	Errs []error
+}
+
+// Error implements the error interface.
+func (b *BatchError) Error() string {
+	var failed int
+	var first error
+	for _, err := range b.Errs {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+
+	return fmt.Sprintf("jac: %d of %d requests failed, first error: %v", failed, len(b.Errs), first)
+}
+
+// Unwrap returns the errors of the failed requests.
+func (b *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range b.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// DoAll makes the given requests concurrently and returns their Responses
+// in the order of the requests.
+//
+// In fail fast mode the first error is returned as is. Otherwise the error
+// is a BatchError when at least one of the requests fails.
+func (c *Client) DoAll(ctx context.Context, reqs []Request, opts BatchOptions) ([]*Response, error) {
+	responses := make([]*Response, len(reqs))
+	errs := make([]error, len(reqs))
+	err := forEach(ctx, len(reqs), opts.Concurrency, opts.FailFast, func(ctx context.Context, i int) error {
+		responses[i], errs[i] = c.Do(ctx, reqs[i])
+		return errs[i]
+	})
+
+	if opts.FailFast {
+		return responses, err
+	}
+	if err != nil {
+		return responses, &BatchError{Errs: errs}
+	}
+
+	return responses, nil
+}
+
+// forEach calls fn for every index in [0, n) using at most limit goroutines
+// at a time and returns the first error returned by fn. A non-positive limit
+// means no limit.
+//
+// If failFast is true the context passed to fn is canceled after the first
+// error and the indices not started yet are skipped.
+func forEach(ctx context.Context, n, limit int, failFast bool, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		if failFast && ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					first = err
+					if failFast {
+						cancel()
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if first == nil && failFast && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return first
+}
@@ -0,0 +1,169 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_forEach(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name     string
+		n        int
+		limit    int
+		failFast bool
+		failAt   int
+		wantMax  int64
+		wantErr  error
+		wantRuns int64
+	}{
+		{name: "limited", n: 20, limit: 3, failAt: -1, wantMax: 3, wantRuns: 20},
+		{name: "unlimited", n: 5, failAt: -1, wantMax: 5, wantRuns: 5},
+		{name: "collect", n: 10, limit: 1, failAt: 2, wantMax: 1, wantErr: errFailed, wantRuns: 10},
+		{name: "fail fast", n: 10, limit: 1, failFast: true, failAt: 2, wantMax: 1, wantErr: errFailed, wantRuns: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, max, runs int64
+			err := forEach(context.Background(), tt.n, tt.limit, tt.failFast, func(ctx context.Context, i int) error {
+				atomic.AddInt64(&runs, 1)
+				cur := atomic.AddInt64(&running, 1)
+				defer atomic.AddInt64(&running, -1)
+				for {
+					m := atomic.LoadInt64(&max)
+					if cur <= m || atomic.CompareAndSwapInt64(&max, m, cur) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond * 5)
+				if i == tt.failAt {
+					return errFailed
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("forEach() error = %v, want %v", err, tt.wantErr)
+			}
+			if max != tt.wantMax {
+				t.Errorf("forEach() max concurrency = %d, want %d", max, tt.wantMax)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("forEach() runs = %d, want %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+type testBatchRequest struct {
+	*GetRequest
+	n int
+}
+
+func (t *testBatchRequest) Path() string {
+	return "/batch/" + strconv.Itoa(t.n)
+}
+
+func TestClient_DoAll(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/batch/3" {
+			w.WriteHeader(400)
+			return
+		}
+		time.Sleep(time.Millisecond)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer svr.Close()
+
+	var reqs []Request
+	for i := 0; i < 6; i++ {
+		reqs = append(reqs, &testBatchRequest{n: i})
+	}
+
+	tests := []struct {
+		name string
+		opts BatchOptions
+	}{
+		{name: "collect all", opts: BatchOptions{Concurrency: 2}},
+		{name: "fail fast", opts: BatchOptions{Concurrency: 1, FailFast: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+			got, err := c.DoAll(context.Background(), reqs, tt.opts)
+			if err == nil {
+				t.Fatalf("Client.DoAll() error = nil")
+			}
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != 400 {
+				t.Errorf("Client.DoAll() error = %v, want StatusError", err)
+			}
+
+			var batchErr *BatchError
+			if errors.As(err, &batchErr) == tt.opts.FailFast {
+				t.Errorf("Client.DoAll() error = %T", err)
+			}
+
+			last := 5
+			if tt.opts.FailFast {
+				last = 2
+			}
+			for i := 0; i <= last; i++ {
+				if i == 3 {
+					continue
+				}
+				if got[i] == nil || string(got[i].Data) != "/batch/"+strconv.Itoa(i) {
+					t.Errorf("Client.DoAll()[%d] = %v", i, got[i])
+				}
+			}
+			if tt.opts.FailFast && got[5] != nil {
+				t.Errorf("Client.DoAll()[5] = %v, want skipped", got[5])
+			}
+		})
+	}
+}
+
+func TestClient_DoAsyncMaxPollers(t *testing.T) {
+	var running, max int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		if cur > atomic.LoadInt64(&max) {
+			atomic.StoreInt64(&max, cur)
+		}
+		time.Sleep(time.Millisecond * 2)
+		w.WriteHeader(202)
+	}))
+	defer svr.Close()
+
+	c := &Client{
+		BaseURL:           svr.URL,
+		DisableLogging:    true,
+		DisableCoalescing: true,
+		Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		Poll:              &PollPolicy{Interval: time.Millisecond, MaxPolls: 2, MaxPollers: 2},
+	}
+	var asyncs []AsyncRequest
+	for i := 0; i < 8; i++ {
+		asyncs = append(asyncs, &neverReadyRequest{})
+	}
+
+	got := c.DoAsyncCollect(context.Background(), asyncs...)
+	if len(got) != 8 {
+		t.Fatalf("Client.DoAsync() results = %d, want 8", len(got))
+	}
+	if max > 2 {
+		t.Errorf("Client.DoAsync() concurrent pollers = %d, want at most 2", max)
+	}
+}
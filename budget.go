@@ -0,0 +1,140 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Budget is a rate limit that can be shared by several Clients drawing on
+// the same quota. Budgets are hierarchical: an event is only allowed once
+// the Budget and all its ancestors allow it, so that for instance a vendor
+// Budget can be nested inside a global outbound Budget.
+type Budget struct {
+	Name    string
+	Limiter *rate.Limiter
+
+	// Parent is the enclosing Budget, if any.
+	Parent *Budget
+}
+
+// NewBudget returns a Budget allowing events up to the rate limit
+// and bursts of at most burst events, nested inside the parent.
+func NewBudget(name string, limit rate.Limit, burst int, parent *Budget) *Budget {
+	return &Budget{Name: name, Limiter: rate.NewLimiter(limit, burst), Parent: parent}
+}
+
+// Wait blocks until the Budget and all its ancestors allow an event or the
+// context is done. The event is reserved on every Budget at once, and the
+// reservations are cancelled when the context is done before they are due.
+func (b *Budget) Wait(ctx context.Context) error {
+	return waitBudgets(ctx, b)
+}
+
+// waitBudgets waits like Budget.Wait for all the Budgets along with their
+// ancestors, reserving a single event on the Budgets found in several chains.
+func waitBudgets(ctx context.Context, budgets ...*Budget) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var (
+		reservations []*rate.Reservation
+		delay        time.Duration
+		seen         = map[*Budget]bool{}
+	)
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	for _, b := range budgets {
+		for x := b; x != nil && !seen[x]; x = x.Parent {
+			seen[x] = true
+			if x.Limiter == nil {
+				continue
+			}
+			r := x.Limiter.ReserveN(now, 1)
+			if !r.OK() {
+				cancel()
+				return fmt.Errorf("jac: budget %s does not allow any event", x.Name)
+			}
+			reservations = append(reservations, r)
+			delay = max(delay, r.DelayFrom(now))
+		}
+	}
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		cancel()
+		return fmt.Errorf("jac: budget wait of %s would exceed the context deadline", delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// BudgetRegistry hands out named Budgets, so that independently configured
+// Clients can share them.
+type BudgetRegistry struct {
+	mu      sync.Mutex
+	budgets map[string]*Budget
+}
+
+// NewBudgetRegistry returns an empty BudgetRegistry.
+func NewBudgetRegistry() *BudgetRegistry {
+	return &BudgetRegistry{budgets: map[string]*Budget{}}
+}
+
+// Define registers a new Budget with the name, nested inside the Budget
+// registered with the parent name, if it is not empty.
+func (r *BudgetRegistry) Define(name string, limit rate.Limit, burst int, parent string) (*Budget, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.budgets[name]; ok {
+		return nil, fmt.Errorf("jac: budget %s is already defined", name)
+	}
+	var p *Budget
+	if parent != "" {
+		var ok bool
+		if p, ok = r.budgets[parent]; !ok {
+			return nil, fmt.Errorf("jac: parent budget %s of %s is not defined", parent, name)
+		}
+	}
+
+	b := NewBudget(name, limit, burst, p)
+	r.budgets[name] = b
+
+	return b, nil
+}
+
+// Get returns the Budget registered with the name or nil if there is none.
+func (r *BudgetRegistry) Get(name string) *Budget {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.budgets[name]
+}
+
+// SetBudget makes every client of the group draw on the Budget as well as
+// on the Budgets it is already nested in. The Budgets of the clients are
+// left unchanged, as they may be shared with clients outside the group.
+// It must be called before the clients are used.
+func (c *ClientGroup) SetBudget(b *Budget) {
+	for _, client := range c.clients {
+		client.groupBudgets = append(client.groupBudgets, b)
+	}
+}
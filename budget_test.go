@@ -0,0 +1,191 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestBudget_Wait(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  func() *Budget
+		events  int
+		min     time.Duration
+		timeout time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "unlimited",
+			budget: func() *Budget { return &Budget{} },
+			events: 10,
+		},
+		{
+			name:   "parent limits",
+			budget: func() *Budget { return NewBudget("child", rate.Inf, 0, NewBudget("parent", 50, 1, nil)) },
+			events: 4,
+			min:    55 * time.Millisecond,
+		},
+		{
+			name:   "child limits",
+			budget: func() *Budget { return NewBudget("child", 50, 1, NewBudget("parent", rate.Inf, 0, nil)) },
+			events: 4,
+			min:    55 * time.Millisecond,
+		},
+		{
+			name:    "deadline",
+			budget:  func() *Budget { return NewBudget("slow", rate.Every(time.Hour), 1, nil) },
+			events:  2,
+			timeout: time.Second,
+			wantErr: true,
+		},
+		{
+			name:    "no burst",
+			budget:  func() *Budget { return NewBudget("closed", 1, 0, nil) },
+			events:  1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			b := tt.budget()
+			start := time.Now()
+			var err error
+			for i := 0; i < tt.events && err == nil; i++ {
+				err = b.Wait(ctx)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Budget.Wait() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed < tt.min {
+				t.Errorf("Budget.Wait() took %s, want at least %s", elapsed, tt.min)
+			}
+		})
+	}
+}
+
+func TestBudget_WaitCancelReservations(t *testing.T) {
+	parent := NewBudget("parent", 10, 1, nil)
+	child := NewBudget("child", rate.Every(time.Hour), 1, parent)
+	if err := child.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The child budget is exhausted, so the wait fails and must give
+	// back the token it reserved on the parent.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := child.Wait(ctx); err == nil {
+		t.Fatal("Budget.Wait() error = nil, want an error")
+	}
+	if tokens := parent.Limiter.Tokens(); tokens < -0.5 {
+		t.Errorf("parent tokens = %f, want the reservation to be cancelled", tokens)
+	}
+}
+
+func TestBudgetRegistry(t *testing.T) {
+	r := NewBudgetRegistry()
+	global, err := r.Define("global", 100, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vendor, err := r.Define("vendor", 10, 1, "global")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vendor.Parent != global || r.Get("vendor") != vendor || r.Get("missing") != nil {
+		t.Errorf("BudgetRegistry.Get() does not return the defined budgets")
+	}
+	if _, err := r.Define("vendor", 1, 1, ""); err == nil {
+		t.Errorf("BudgetRegistry.Define() duplicate error = nil")
+	}
+	if _, err := r.Define("other", 1, 1, "missing"); err == nil {
+		t.Errorf("BudgetRegistry.Define() unknown parent error = nil")
+	}
+}
+
+func TestClientGroup_SetBudget(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer svr.Close()
+
+	newClient := func(path string) *Client {
+		return &Client{
+			BaseURL:           svr.URL + path,
+			DisableLogging:    true,
+			DisableCoalescing: true,
+			Limiter:           rate.NewLimiter(rate.Inf, 0),
+			Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		}
+	}
+	a, b := newClient("/a"), newClient("/b")
+	g := NewClientGroup(a, b)
+	g.SetBudget(NewBudget("vendor", 40, 1, nil))
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for _, c := range []*Client{a, b, a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Do(context.Background(), &testItemsRequest{path: "/items"}); err != nil {
+				t.Errorf("Client.Do() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 4 requests with a burst of 1 at 40 per second need at least 75ms.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond || len(times) != 4 {
+		t.Errorf("shared budget allowed %d requests in %s", len(times), elapsed)
+	}
+}
+
+func TestClientGroup_SetBudget_nested(t *testing.T) {
+	global := NewBudget("global", 100, 1, nil)
+	vendor := NewBudget("vendor", 100, 1, nil)
+	tenant := NewBudget("tenant", 100, 1, vendor)
+	a := &Client{Name: "a", Budget: tenant, Limiter: rate.NewLimiter(rate.Inf, 0)}
+	b := &Client{Name: "b", Budget: vendor, Limiter: rate.NewLimiter(rate.Inf, 0)}
+	outside := &Client{Name: "outside", Budget: vendor, Limiter: rate.NewLimiter(rate.Inf, 0)}
+
+	g := NewClientGroup(a, b)
+	g.SetBudget(global)
+	g.SetBudget(vendor)
+	if a.Budget != tenant || tenant.Parent != vendor || vendor.Parent != nil {
+		t.Fatalf("ClientGroup.SetBudget() changed the budget hierarchy")
+	}
+
+	if err := a.waitLimits(context.Background()); err != nil {
+		t.Fatalf("Client.waitLimits() error = %v", err)
+	}
+	for _, x := range []*Budget{global, vendor, tenant} {
+		// Every budget is drawn on once, even when it is in several chains.
+		if tokens := x.Limiter.Tokens(); tokens < -0.5 || tokens > 0.5 {
+			t.Errorf("Client.waitLimits() %s tokens = %.2f, want 0", x.Name, tokens)
+		}
+	}
+
+	if err := outside.waitLimits(context.Background()); err != nil {
+		t.Fatalf("Client.waitLimits() error = %v", err)
+	}
+	if tokens := global.Limiter.Tokens(); tokens < -0.5 {
+		t.Errorf("Client.waitLimits() drew on the group budget for a client outside the group")
+	}
+}
@@ -0,0 +1,244 @@
+package jac
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// sealedVersion is the version of the encrypted cache entry format.
+const sealedVersion = 1
+
+var errSealedEntry = errors.New("jac: malformed encrypted cache entry")
+
+// Keyring holds the keys used for encrypting cache entries.
+//
+// Every entry records the ID of the key it is encrypted with, so the
+// entries encrypted with an older key can still be decrypted after
+// rotating to a new primary key as long as the older key is kept.
+type Keyring struct {
+	// Primary is the ID of the key new entries are encrypted with.
+	Primary string
+
+	// Keys maps key IDs to 16, 24 or 32 byte long AES keys.
+	Keys map[string][]byte
+}
+
+// sealer encrypts and authenticates cache items with AES-GCM.
+type sealer struct {
+	mu      sync.RWMutex
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+func newSealer(keys Keyring) (*sealer, error) {
+	s := &sealer{aeads: map[string]cipher.AEAD{}}
+	for id, key := range keys.Keys {
+		if err := s.add(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := s.aeads[keys.Primary]; !ok {
+		return nil, fmt.Errorf("jac: primary key %q not found in keyring", keys.Primary)
+	}
+	s.primary = keys.Primary
+
+	return s, nil
+}
+
+func (s *sealer) add(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("jac: invalid key id %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("jac: key %q: %w", id, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.aeads[id] = aead
+
+	return nil
+}
+
+// rotate adds the key to the sealer and makes it the primary key.
+func (s *sealer) rotate(id string, key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.add(id, key); err != nil {
+		return err
+	}
+	s.primary = id
+
+	return nil
+}
+
+// seal encrypts the item with the primary key. The cache key is
+// authenticated along with the entry, so entries can not be swapped.
+func (s *sealer) seal(key string, item *CacheItem) ([]byte, error) {
+	plain, err := item.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	id, aead := s.primary, s.aeads[s.primary]
+	s.mu.RUnlock()
+
+	header := append([]byte{sealedVersion, byte(len(id))}, id...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	aad := append(append([]byte{}, header...), key...)
+	sealed := append(header, nonce...)
+
+	return aead.Seal(sealed, nonce, plain, aad), nil
+}
+
+// open decrypts and authenticates an entry sealed for the cache key.
+func (s *sealer) open(key string, sealed []byte) (*CacheItem, error) {
+	if len(sealed) < 2 || sealed[0] != sealedVersion || len(sealed) < 2+int(sealed[1]) {
+		return nil, errSealedEntry
+	}
+	header := sealed[:2+int(sealed[1])]
+	id := string(header[2:])
+
+	s.mu.RLock()
+	aead, ok := s.aeads[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("jac: unknown cache key id %q", id)
+	}
+
+	rest := sealed[len(header):]
+	if len(rest) < aead.NonceSize() {
+		return nil, errSealedEntry
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	aad := append(append([]byte{}, header...), key...)
+	plain, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &CacheItem{}
+	if err := item.UnmarshalBinary(plain); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// EncryptedCache is a Cache that encrypts and authenticates every
+// item with AES-GCM before storing it in the underlying Cache.
+//
+// The underlying Cache only ever sees a Response whose Data is the
+// encrypted item, along with the expiration of the item.
+// Items that fail to decrypt are treated as cache misses.
+type EncryptedCache struct {
+	cache  Cache
+	sealer *sealer
+}
+
+// NewEncryptedCache returns an EncryptedCache wrapping the given Cache.
+func NewEncryptedCache(cache Cache, keys Keyring) (*EncryptedCache, error) {
+	s, err := newSealer(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedCache{cache: cache, sealer: s}, nil
+}
+
+// Get implements the Cache interface.
+func (e *EncryptedCache) Get(key string) *Response {
+	sealed := e.cache.Get(key)
+	if sealed == nil {
+		return nil
+	}
+	item, err := e.sealer.open(key, sealed.Data)
+	if err != nil {
+		return nil
+	}
+
+	return item.Response
+}
+
+// Set implements the Cache interface.
+func (e *EncryptedCache) Set(key string, item *CacheItem) {
+	sealed, err := e.sealer.seal(key, item)
+	if err != nil {
+		return
+	}
+	e.cache.Set(key, &CacheItem{Response: &Response{Data: sealed}, Expiration: item.Expiration})
+}
+
+// StopEvictor stops the evictor of the underlying Cache, if it has one.
+func (e *EncryptedCache) StopEvictor() {
+	if x, ok := e.cache.(interface{ StopEvictor() }); ok {
+		x.StopEvictor()
+	}
+}
+
+// Rotate adds the key to the keyring and encrypts new items with it.
+func (e *EncryptedCache) Rotate(id string, key []byte) error {
+	return e.sealer.rotate(id, key)
+}
+
+// EncryptedStore is the CacheStore counterpart of EncryptedCache.
+type EncryptedStore struct {
+	store  CacheStore
+	sealer *sealer
+}
+
+// NewEncryptedStore returns an EncryptedStore wrapping the given CacheStore.
+func NewEncryptedStore(store CacheStore, keys Keyring) (*EncryptedStore, error) {
+	s, err := newSealer(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedStore{store: store, sealer: s}, nil
+}
+
+// Get implements the CacheStore interface.
+func (e *EncryptedStore) Get(ctx context.Context, key string) (*CacheItem, error) {
+	sealed, err := e.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if sealed.Response == nil {
+		return nil, errSealedEntry
+	}
+
+	return e.sealer.open(key, sealed.Response.Data)
+}
+
+// Set implements the CacheStore interface.
+func (e *EncryptedStore) Set(ctx context.Context, key string, item *CacheItem) error {
+	sealed, err := e.sealer.seal(key, item)
+	if err != nil {
+		return err
+	}
+
+	return e.store.Set(ctx, key, &CacheItem{Response: &Response{Data: sealed}, Expiration: item.Expiration})
+}
+
+// Delete implements the CacheStore interface.
+func (e *EncryptedStore) Delete(ctx context.Context, key string) error {
+	return e.store.Delete(ctx, key)
+}
+
+// Rotate adds the key to the keyring and encrypts new items with it.
+func (e *EncryptedStore) Rotate(id string, key []byte) error {
+	return e.sealer.rotate(id, key)
+}
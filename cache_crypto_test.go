@@ -0,0 +1,112 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+var (
+	testKeyA = bytes.Repeat([]byte{'a'}, 32)
+	testKeyB = bytes.Repeat([]byte{'b'}, 16)
+)
+
+func TestNewEncryptedCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    Keyring
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			keys: Keyring{Primary: "a", Keys: map[string][]byte{"a": testKeyA, "b": testKeyB}},
+		},
+		{
+			name:    "missing primary",
+			keys:    Keyring{Primary: "c", Keys: map[string][]byte{"a": testKeyA}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key size",
+			keys:    Keyring{Primary: "a", Keys: map[string][]byte{"a": []byte("short")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEncryptedCache(newTestCache(t), tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEncryptedCache() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newTestCache(t *testing.T) *InMemoryCache {
+	cache := NewInMemoryCache()
+	t.Cleanup(cache.StopEvictor)
+	return cache
+}
+
+func TestEncryptedCache(t *testing.T) {
+	inner := newTestCache(t)
+	cache, err := NewEncryptedCache(inner, Keyring{Primary: "a", Keys: map[string][]byte{"a": testKeyA}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte(`{"email":"jane@example.com"}`)
+	cache.Set("key", newCacheItem(&Response{Data: secret, StatusCode: 200}, time.Hour))
+
+	if stored := inner.Get("key"); stored == nil || bytes.Contains(stored.Data, []byte("jane")) {
+		t.Errorf("EncryptedCache.Set() stored plaintext: %q", stored.Data)
+	}
+	if got := cache.Get("key"); got == nil || !bytes.Equal(got.Data, secret) || got.StatusCode != 200 {
+		t.Errorf("EncryptedCache.Get() = %+v", got)
+	}
+
+	// An entry moved to another key must not authenticate.
+	inner.Set("other", newCacheItem(inner.Get("key"), time.Hour))
+	if got := cache.Get("other"); got != nil {
+		t.Errorf("EncryptedCache.Get() swapped entry = %+v, want nil", got)
+	}
+
+	if err := cache.Rotate("b", testKeyB); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("new", newCacheItem(&Response{Data: []byte("new")}, time.Hour))
+	if got := cache.Get("key"); got == nil || !bytes.Equal(got.Data, secret) {
+		t.Errorf("EncryptedCache.Get() after rotation = %+v", got)
+	}
+
+	// A cache without the rotated key can not read the new entries.
+	stale, _ := NewEncryptedCache(inner, Keyring{Primary: "a", Keys: map[string][]byte{"a": testKeyA}})
+	if got := stale.Get("new"); got != nil {
+		t.Errorf("EncryptedCache.Get() unknown key id = %+v, want nil", got)
+	}
+	if got := stale.Get("key"); got == nil {
+		t.Errorf("EncryptedCache.Get() = nil, want entry")
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryCacheStore()
+	store, err := NewEncryptedStore(inner, Keyring{Primary: "a", Keys: map[string][]byte{"a": testKeyA}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Set(ctx, "key", newCacheItem(&Response{Data: []byte("secret")}, time.Hour)); err != nil {
+		t.Fatalf("EncryptedStore.Set() error = %v", err)
+	}
+	item, err := store.Get(ctx, "key")
+	if err != nil || string(item.Response.Data) != "secret" {
+		t.Errorf("EncryptedStore.Get() = %v, %v", item, err)
+	}
+	raw, _ := inner.Get(ctx, "key")
+	if bytes.Contains(raw.Response.Data, []byte("secret")) {
+		t.Errorf("EncryptedStore.Set() stored plaintext")
+	}
+}
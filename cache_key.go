@@ -0,0 +1,178 @@
+package jac
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheableRequest is the interface implemented by Requests whose
+// Responses can be cached for the duration returned by TTL.
+//
+// The cache key is derived from the outgoing HTTP Request with
+// DeriveCacheKey unless the Request also implements CacheKeyer,
+// in which case its CacheKey is used instead.
+type CacheableRequest interface {
+	Request
+	TTL() time.Duration
+}
+
+// NegativeCacheRequest is implemented by CacheableRequests whose unsuccessful
+// Responses should be cached as well. ErrorTTLs maps the status codes to cache
+// to their TTLs, for instance 404 for 30 seconds and 410 for an hour.
+//
+// A cached unsuccessful Response is returned along with the same StatusError
+// the request would return when it is not cached.
+type NegativeCacheRequest interface {
+	CacheableRequest
+	ErrorTTLs() map[int]time.Duration
+}
+
+// CacheKeyer is implemented by Requests that provide their own cache key
+// rather than relying on the derived one.
+type CacheKeyer interface {
+	CacheKey() string
+}
+
+// VaryRequest is implemented by Requests whose Responses vary on the
+// values of the returned header names, similar to the Vary response header.
+// The values of these headers become part of the derived cache key.
+type VaryRequest interface {
+	Vary() []string
+}
+
+// credentialHeaders are the headers whose values are always treated as
+// credentials when deriving a cache key.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// DeriveCacheKey returns a cache key for the given HTTP Request.
+//
+// The key is the hex encoded SHA-256 digest of the request method,
+// the normalized URL with its query parameters sorted, the body,
+// the values of the vary headers and the credentials of the request.
+// Credentials are the Authorization, Proxy-Authorization and Cookie
+// headers along with the headers listed in creds.
+// Secrets never appear in the key in plain text.
+func DeriveCacheKey(r *http.Request, vary []string, creds ...string) string {
+	h := sha256.New()
+	writeKeyPart(h, strings.ToUpper(r.Method))
+	writeKeyPart(h, normalizeURL(r.URL))
+	writeKeyPart(h, hashBody(r))
+
+	for _, name := range sortedHeaderNames(vary) {
+		writeKeyPart(h, name+":"+strings.Join(r.Header.Values(name), ","))
+	}
+
+	ch := sha256.New()
+	creds = append(creds[:len(creds):len(creds)], credentialHeaders...)
+	for _, name := range sortedHeaderNames(creds) {
+		writeKeyPart(ch, name+":"+strings.Join(r.Header.Values(name), ","))
+	}
+	writeKeyPart(h, hex.EncodeToString(ch.Sum(nil)))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheKey returns the custom cache key of the Request if it has one and
+// the derived key of the HTTP Request otherwise.
+//
+// Headers set by the Client, whether default headers or set by the
+// Authorizer, are hashed as credentials, so that clients sharing a
+// CacheStore with different API keys never share their Responses. Only
+// the headers set by the Request itself are left out.
+func (c *Client) cacheKey(req Request, header http.Header, r *http.Request) string {
+	if keyer, ok := req.(CacheKeyer); ok {
+		return keyer.CacheKey()
+	}
+
+	var vary []string
+	if v, ok := req.(VaryRequest); ok {
+		vary = v.Vary()
+	}
+
+	own := map[string]bool{}
+	for name := range header {
+		own[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range c.Headers {
+		delete(own, http.CanonicalHeaderKey(name))
+	}
+
+	var creds []string
+	for name := range r.Header {
+		if !own[http.CanonicalHeaderKey(name)] {
+			creds = append(creds, name)
+		}
+	}
+
+	return DeriveCacheKey(r, vary, creds...)
+}
+
+// normalizeURL returns the string representation of the URL with a lower case
+// scheme and host, without the default port and fragment and with a sorted query.
+func normalizeURL(u *url.URL) string {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = normalizeHost(u)
+	if n.Path == "" {
+		n.Path = "/"
+	}
+	n.RawQuery = n.Query().Encode()
+	n.Fragment = ""
+	n.RawFragment = ""
+	n.User = nil
+
+	return n.String()
+}
+
+// hashBody returns the hex encoded digest of the request body
+// or an empty string if the body can not be replayed.
+func hashBody(r *http.Request) string {
+	if r.GetBody == nil {
+		return ""
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sortedHeaderNames canonicalizes, deduplicates and sorts header names.
+func sortedHeaderNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	sorted := make([]string, 0, len(names))
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if !seen[name] {
+			seen[name] = true
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// writeKeyPart writes a length prefixed part into the hash
+// so that adjacent parts can not be confused with each other.
+func writeKeyPart(h hash.Hash, part string) {
+	var prefix [8]byte
+	binary.LittleEndian.PutUint64(prefix[:], uint64(len(part)))
+	h.Write(prefix[:])
+	io.WriteString(h, part)
+}
@@ -0,0 +1,169 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newKeyRequest(method, u string, header http.Header, body string) *http.Request {
+	req, _ := http.NewRequest(method, u, strings.NewReader(body))
+	for k, vals := range header {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
+	return req
+}
+
+func TestDeriveCacheKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     *http.Request
+		b     *http.Request
+		vary  []string
+		creds []string
+		equal bool
+	}{
+		{
+			name:  "query order",
+			a:     newKeyRequest("GET", "https://api.x.com/items?b=2&a=1", nil, ""),
+			b:     newKeyRequest("get", "https://API.x.com:443/items?a=1&b=2#frag", nil, ""),
+			equal: true,
+		},
+		{
+			name:  "different path",
+			a:     newKeyRequest("GET", "https://api.x.com/items", nil, ""),
+			b:     newKeyRequest("GET", "https://api.x.com/items/1", nil, ""),
+			equal: false,
+		},
+		{
+			name:  "different credentials",
+			a:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"Authorization": {"Bearer a"}}, ""),
+			b:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"Authorization": {"Bearer b"}}, ""),
+			equal: false,
+		},
+		{
+			name:  "different custom credentials",
+			a:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"X-Api-Key": {"a"}}, ""),
+			b:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"X-Api-Key": {"b"}}, ""),
+			creds: []string{"x-api-key"},
+			equal: false,
+		},
+		{
+			name:  "unselected header",
+			a:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"X-Request-Id": {"1"}}, ""),
+			b:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"X-Request-Id": {"2"}}, ""),
+			equal: true,
+		},
+		{
+			name:  "vary header",
+			a:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"Accept-Language": {"en"}}, ""),
+			b:     newKeyRequest("GET", "https://api.x.com/items", http.Header{"Accept-Language": {"de"}}, ""),
+			vary:  []string{"accept-language"},
+			equal: false,
+		},
+		{
+			name:  "different body",
+			a:     newKeyRequest("POST", "https://api.x.com/search", nil, `{"q":"a"}`),
+			b:     newKeyRequest("POST", "https://api.x.com/search", nil, `{"q":"b"}`),
+			equal: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := DeriveCacheKey(tt.a, tt.vary, tt.creds...)
+			b := DeriveCacheKey(tt.b, tt.vary, tt.creds...)
+			if (a == b) != tt.equal {
+				t.Errorf("DeriveCacheKey() equal = %v, want %v", a == b, tt.equal)
+			}
+			if strings.Contains(a, "Bearer") {
+				t.Errorf("DeriveCacheKey() = %s, contains credentials", a)
+			}
+		})
+	}
+}
+
+type testTTLRequest struct {
+	*GetRequest
+}
+
+func (t *testTTLRequest) Path() string {
+	return "/ttl"
+}
+
+func (t *testTTLRequest) TTL() time.Duration {
+	return time.Hour
+}
+
+type tenantAuth struct{}
+
+func (a *tenantAuth) Authorize(r *http.Request) error {
+	r.Header.Set("X-Tenant-Key", r.Context().Value(tenantKey{}).(string))
+	return nil
+}
+
+type tenantKey struct{}
+
+func TestClient_DoDerivedCacheKey(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		fmt.Fprint(w, r.Header.Get("X-Tenant-Key"))
+	}))
+	defer svr.Close()
+
+	c := &Client{BaseURL: svr.URL, Authorizer: &tenantAuth{}, DisableLogging: true}
+	for _, tenant := range []string{"a", "b", "a", "b"} {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		res, err := c.Do(ctx, &testTTLRequest{})
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		if string(res.Data) != tenant {
+			t.Errorf("Client.Do() = %s, want %s", res.Data, tenant)
+		}
+	}
+
+	if hits != 2 {
+		t.Errorf("Client.Do() server hits = %d, want 2", hits)
+	}
+}
+
+func TestClient_DoDerivedCacheKey_defaultHeaders(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		fmt.Fprint(w, r.Header.Get("X-Api-Key"))
+	}))
+	defer svr.Close()
+
+	store := NewMemoryCacheStore()
+	clients := map[string]*Client{}
+	for _, key := range []string{"a", "b"} {
+		clients[key] = &Client{
+			BaseURL:        svr.URL,
+			Headers:        http.Header{"X-Api-Key": {key}},
+			CacheStore:     store,
+			DisableLogging: true,
+		}
+	}
+	for _, key := range []string{"a", "b", "a", "b"} {
+		res, err := clients[key].Do(context.Background(), &testTTLRequest{})
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		if string(res.Data) != key {
+			t.Errorf("Client.Do() = %s, want %s", res.Data, key)
+		}
+	}
+
+	if hits != 2 {
+		t.Errorf("Client.Do() server hits = %d, want 2", hits)
+	}
+}
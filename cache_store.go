@@ -0,0 +1,230 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// cacheItemVersion is the version of the CacheItem encoding.
+const cacheItemVersion = 1
+
+// ErrCacheMiss is returned by CacheStores when the key is not
+// present in the store or its item has expired.
+var ErrCacheMiss = errors.New("jac: cache miss")
+
+// CacheStore is the context aware successor of the Cache interface
+// suitable for remote stores such as Redis or memcached.
+//
+// Implementations return ErrCacheMiss from Get when there is no
+// unexpired item for the key. Any other error is considered a failure
+// of the store; the Client reports it through OnCacheError and makes
+// the request as if the item was not cached.
+//
+// Remote implementations can use the MarshalBinary and UnmarshalBinary
+// methods of CacheItem to serialize the items.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CacheItem, error)
+	Set(ctx context.Context, key string, item *CacheItem) error
+	Delete(ctx context.Context, key string) error
+}
+
+// cacheItemJSON is the stable encoding of a CacheItem.
+type cacheItemJSON struct {
+	Version      int           `json:"v"`
+	Expiration   time.Time     `json:"exp"`
+	URL          string        `json:"url,omitempty"`
+	RequestURI   string        `json:"uri,omitempty"`
+	Data         []byte        `json:"data"`
+	Header       http.Header   `json:"header,omitempty"`
+	Duration     time.Duration `json:"dur"`
+	AttemptCount int           `json:"attempts"`
+	StatusCode   int           `json:"status"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c *CacheItem) MarshalJSON() ([]byte, error) {
+	enc := cacheItemJSON{Version: cacheItemVersion, Expiration: c.Expiration}
+	if r := c.Response; r != nil {
+		if r.URL != nil {
+			enc.URL = r.URL.String()
+		}
+		enc.RequestURI = r.RequestURI
+		enc.Data = r.Data
+		enc.Header = r.Header
+		enc.Duration = r.Duration
+		enc.AttemptCount = r.AttemptCount
+		enc.StatusCode = r.StatusCode
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *CacheItem) UnmarshalJSON(data []byte) error {
+	var enc cacheItemJSON
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	if enc.Version != cacheItemVersion {
+		return fmt.Errorf("jac: unsupported cache item version %d", enc.Version)
+	}
+
+	var u *url.URL
+	if enc.URL != "" {
+		var err error
+		if u, err = url.Parse(enc.URL); err != nil {
+			return err
+		}
+	}
+
+	c.Expiration = enc.Expiration
+	c.Response = &Response{
+		URL:          u,
+		RequestURI:   enc.RequestURI,
+		Data:         enc.Data,
+		Header:       enc.Header,
+		Duration:     enc.Duration,
+		AttemptCount: enc.AttemptCount,
+		StatusCode:   enc.StatusCode,
+	}
+
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (c *CacheItem) MarshalBinary() ([]byte, error) {
+	return c.MarshalJSON()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (c *CacheItem) UnmarshalBinary(data []byte) error {
+	return c.UnmarshalJSON(data)
+}
+
+// cacheAdapter adapts a Cache to the CacheStore interface.
+type cacheAdapter struct {
+	cache Cache
+}
+
+// AdaptCache returns a CacheStore backed by the given Cache.
+//
+// As the Cache interface does not expose the expiration of its items,
+// the items returned by the CacheStore have a zero Expiration.
+func AdaptCache(cache Cache) CacheStore {
+	return &cacheAdapter{cache: cache}
+}
+
+// Get implements the CacheStore interface.
+func (a *cacheAdapter) Get(ctx context.Context, key string) (*CacheItem, error) {
+	response := a.cache.Get(key)
+	if response == nil {
+		return nil, ErrCacheMiss
+	}
+
+	return &CacheItem{Response: response}, nil
+}
+
+// Set implements the CacheStore interface.
+func (a *cacheAdapter) Set(ctx context.Context, key string, item *CacheItem) error {
+	a.cache.Set(key, item)
+	return nil
+}
+
+// Delete implements the CacheStore interface by replacing
+// the item with an expired one.
+func (a *cacheAdapter) Delete(ctx context.Context, key string) error {
+	a.cache.Set(key, &CacheItem{})
+	return nil
+}
+
+// MemoryCacheStore is an in-process CacheStore intended for tests.
+//
+// Items are stored in their encoded form the same way a remote store
+// would keep them, so encoding issues surface in tests. Err can be set
+// to simulate a failing store.
+type MemoryCacheStore struct {
+	mu    sync.Mutex
+	items map[string][]byte
+
+	// Err is returned by every method of the store when it is set.
+	Err error
+}
+
+// NewMemoryCacheStore returns an empty MemoryCacheStore.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{items: map[string][]byte{}}
+}
+
+// Get implements the CacheStore interface.
+func (m *MemoryCacheStore) Get(ctx context.Context, key string) (*CacheItem, error) {
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	data, ok := m.items[key]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	item := &CacheItem{}
+	if err := item.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if item.isExpired() {
+		m.Delete(ctx, key)
+		return nil, ErrCacheMiss
+	}
+
+	return item, nil
+}
+
+// Set implements the CacheStore interface.
+func (m *MemoryCacheStore) Set(ctx context.Context, key string, item *CacheItem) error {
+	if err := m.check(ctx); err != nil {
+		return err
+	}
+	data, err := item.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.items[key] = data
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Delete implements the CacheStore interface.
+func (m *MemoryCacheStore) Delete(ctx context.Context, key string) error {
+	if err := m.check(ctx); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	delete(m.items, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Len returns the number of items in the store including the expired ones.
+func (m *MemoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.items)
+}
+
+func (m *MemoryCacheStore) check(ctx context.Context) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	return ctx.Err()
+}
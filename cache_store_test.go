@@ -0,0 +1,141 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheItem_MarshalBinary(t *testing.T) {
+	u, _ := url.Parse("https://api.x.com/items?a=1")
+	item := &CacheItem{
+		Expiration: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Response: &Response{
+			URL:          u,
+			RequestURI:   "/items?a=1",
+			Data:         []byte{0, 1, 2, 'x'},
+			Header:       http.Header{"Content-Type": {"application/json"}},
+			Duration:     time.Second,
+			AttemptCount: 2,
+			StatusCode:   200,
+		},
+	}
+
+	data, err := item.MarshalBinary()
+	if err != nil {
+		t.Fatalf("CacheItem.MarshalBinary() error = %v", err)
+	}
+	got := &CacheItem{}
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("CacheItem.UnmarshalBinary() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, item) {
+		t.Errorf("CacheItem.UnmarshalBinary() = %+v, want %+v", got.Response, item.Response)
+	}
+
+	if err := got.UnmarshalBinary([]byte(`{"v":99}`)); err == nil {
+		t.Errorf("CacheItem.UnmarshalBinary() unknown version error = nil")
+	}
+}
+
+func TestAdaptCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewInMemoryCache()
+	defer cache.StopEvictor()
+	store := AdaptCache(cache)
+
+	if _, err := store.Get(ctx, "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get() error = %v, want %v", err, ErrCacheMiss)
+	}
+	_ = store.Set(ctx, "key", newCacheItem(&Response{Data: []byte("a")}, time.Hour))
+	item, err := store.Get(ctx, "key")
+	if err != nil || string(item.Response.Data) != "a" {
+		t.Errorf("Get() = %v, %v", item, err)
+	}
+	_ = store.Delete(ctx, "key")
+	if _, err := store.Get(ctx, "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get() after Delete error = %v, want %v", err, ErrCacheMiss)
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore()
+
+	_ = store.Set(ctx, "expired", newCacheItem(&Response{}, -time.Second))
+	if _, err := store.Get(ctx, "expired"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Get() expired error = %v, want %v", err, ErrCacheMiss)
+	}
+	if store.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", store.Len())
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := store.Set(canceled, "key", newCacheItem(&Response{}, time.Hour)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Set() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestClient_DoCacheStore(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, atomic.AddInt64(&hits, 1))
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		storeErr error
+		want     []string
+	}{
+		{
+			name: "shared store",
+			want: []string{"1", "1", "1"},
+		},
+		{
+			name:     "failing store",
+			storeErr: errors.New("connection refused"),
+			want:     []string{"1", "2", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&hits, 0)
+			store := NewMemoryCacheStore()
+			store.Err = tt.storeErr
+			var cacheErrs int
+			newClient := func() *Client {
+				return &Client{
+					BaseURL:        svr.URL,
+					DisableLogging: true,
+					CacheStore:     store,
+					OnCacheError:   func(string, error) { cacheErrs++ },
+				}
+			}
+
+			var got []string
+			for _, c := range []*Client{newClient(), newClient(), newClient()} {
+				res, err := c.Do(context.Background(), &testTTLRequest{})
+				if err != nil {
+					t.Fatalf("Client.Do() error = %v", err)
+				}
+				got = append(got, string(res.Data))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.Do() = %v, want %v", got, tt.want)
+			}
+			if (cacheErrs > 0) != (tt.storeErr != nil) {
+				t.Errorf("Client.OnCacheError() calls = %d", cacheErrs)
+			}
+		})
+	}
+}
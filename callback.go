@@ -0,0 +1,221 @@
+package jac
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defCallbackTimeout is the default duration to wait for
+	// a callback before falling back to polling.
+	defCallbackTimeout = 5 * time.Minute
+
+	// maxCallbackSize is the maximum accepted size of a callback body.
+	maxCallbackSize = 10 << 20
+)
+
+// CorrelationHeader is the header a callback can use to specify its
+// correlation ID instead of the last segment of the callback URL path.
+const CorrelationHeader = "X-Correlation-ID"
+
+// CallbackRequest is an AsyncRequest that can register a callback URL,
+// which the API calls when the async operation completes.
+type CallbackRequest interface {
+	AsyncRequest
+
+	// WithCallback returns the AsyncRequest that registers the callback URL
+	// along with the correlation ID in the submit request.
+	WithCallback(callbackURL, correlationID string) AsyncRequest
+}
+
+// CallbackReceiver receives the completion callbacks of CallbackRequests.
+//
+// A CallbackReceiver is an http.Handler that can be mounted on an existing
+// server or started as a standalone server with Listen. The callback URL of
+// a request is the URL of the receiver followed by the correlation ID.
+type CallbackReceiver struct {
+	// URL is the externally reachable URL the receiver is served at.
+	URL string
+
+	// Timeout is the duration to wait for a callback before falling back
+	// to polling. Default is 5 minutes.
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]chan *Response
+	srv     *http.Server
+}
+
+// NewCallbackReceiver returns a CallbackReceiver served at the given URL.
+func NewCallbackReceiver(u string) *CallbackReceiver {
+	return &CallbackReceiver{URL: u}
+}
+
+// Listen starts serving the receiver on the given address in the background.
+// If the URL of the receiver is empty it is set to the address of the listener.
+func (r *CallbackReceiver) Listen(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.URL == "" {
+		r.URL = "http://" + l.Addr().String()
+	}
+	r.srv = &http.Server{Handler: r, ReadHeaderTimeout: 10 * time.Second}
+	srv := r.srv
+	r.mu.Unlock()
+
+	go srv.Serve(l)
+
+	return nil
+}
+
+// Close stops the server started by Listen.
+func (r *CallbackReceiver) Close() error {
+	r.mu.Lock()
+	srv := r.srv
+	r.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+
+	return srv.Close()
+}
+
+// CallbackURL returns the callback URL for the correlation ID.
+func (r *CallbackReceiver) CallbackURL(correlationID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return strings.TrimSuffix(r.URL, "/") + "/" + correlationID
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// The body and header of the callback are delivered to the waiting request
+// as a Response. Callbacks for unknown correlation IDs are rejected with
+// 404 Not Found.
+func (r *CallbackReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(CorrelationHeader)
+	if id == "" {
+		id = req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	}
+
+	r.mu.Lock()
+	ch, ok := r.pending[id]
+	r.mu.Unlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxCallbackSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case ch <- &Response{URL: req.URL, Data: data, Header: req.Header, StatusCode: http.StatusOK}:
+	default:
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// register starts waiting for the callback with the correlation ID.
+func (r *CallbackReceiver) register(id string) chan *Response {
+	ch := make(chan *Response, 1)
+	r.mu.Lock()
+	if r.pending == nil {
+		r.pending = map[string]chan *Response{}
+	}
+	r.pending[id] = ch
+	r.mu.Unlock()
+
+	return ch
+}
+
+// unregister stops waiting for the callback with the correlation ID.
+func (r *CallbackReceiver) unregister(id string) {
+	r.mu.Lock()
+	delete(r.pending, id)
+	r.mu.Unlock()
+}
+
+func (r *CallbackReceiver) timeout() time.Duration {
+	if r.Timeout <= 0 {
+		return defCallbackTimeout
+	}
+
+	return r.Timeout
+}
+
+// DoAsyncCallback is like DoAsync but completes CallbackRequests when their
+// callback arrives at the receiver instead of polling them.
+//
+// Every CallbackRequest is submitted with a callback URL of the receiver and
+// a unique correlation ID. Callbacks are passed to IsReady like poll
+// responses. If no ready callback arrives within the Timeout of the
+// receiver, the request is polled as usual. Other requests are always polled.
+func (c *Client) DoAsyncCallback(ctx context.Context, recv *CallbackReceiver, asyncs ...AsyncRequest) chan *AsyncResponse {
+	c.once.Do(c.init)
+	ch := make(chan *AsyncResponse)
+	go c.doAsyncs(ch, ctx, asyncs, func(ctx context.Context, _ int, request AsyncRequest, p *poller) *AsyncResponse {
+		return c.callback(ctx, recv, request, p)
+	})
+
+	return ch
+}
+
+func (c *Client) callback(ctx context.Context, recv *CallbackReceiver, request AsyncRequest, p *poller) *AsyncResponse {
+	cb, ok := request.(CallbackRequest)
+	if !ok {
+		return c.poll(ctx, request, p)
+	}
+
+	id := uuid.NewString()
+	wait := recv.register(id)
+	defer recv.unregister(id)
+
+	request = cb.WithCallback(recv.CallbackURL(id), id)
+	response, err := c.Do(ctx, request)
+	p.polls++
+	if err != nil {
+		return &AsyncResponse{Err: err}
+	}
+
+	timer := time.NewTimer(recv.timeout())
+	defer timer.Stop()
+	for response != nil {
+		ready, err := request.IsReady(response)
+		if err != nil {
+			return &AsyncResponse{Err: err}
+		}
+		if ready {
+			return c.doAsyncReady(ctx, request.OnReady())
+		}
+
+		select {
+		case response = <-wait:
+		case <-timer.C:
+			response = nil
+		case <-ctx.Done():
+			return &AsyncResponse{Err: ctx.Err()}
+		}
+	}
+
+	if err := p.next(ctx, 0); err != nil {
+		return &AsyncResponse{Err: err}
+	}
+
+	return c.poll(ctx, request, p)
+}
@@ -0,0 +1,161 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCallbackRequest struct {
+	*PostRequest
+	id          string
+	callbackURL string
+}
+
+func (t *testCallbackRequest) Path() string {
+	return "/jobs/" + t.id
+}
+
+func (t *testCallbackRequest) Body() []byte {
+	body, _ := json.Marshal(map[string]string{"callback": t.callbackURL})
+	return body
+}
+
+func (t *testCallbackRequest) IsReady(res *Response) (bool, error) {
+	return bytes.Contains(res.Data, []byte(`"done":true`)), nil
+}
+
+func (t *testCallbackRequest) OnReady() Request {
+	return &testResultRequest{id: t.id}
+}
+
+func (t *testCallbackRequest) WithCallback(callbackURL, correlationID string) AsyncRequest {
+	r := *t
+	r.callbackURL = callbackURL
+	return &r
+}
+
+type testResultRequest struct {
+	*GetRequest
+	id string
+}
+
+func (t *testResultRequest) Path() string {
+	return "/results/" + t.id
+}
+
+func newCallbackServer(callback bool, polls *int64) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Callback string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		n := atomic.AddInt64(polls, 1)
+		if !callback {
+			if n >= 3 {
+				fmt.Fprint(w, `{"done":true}`)
+				return
+			}
+			w.WriteHeader(202)
+			return
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			res, err := http.Post(body.Callback, "application/json", bytes.NewReader([]byte(`{"done":true}`)))
+			if err == nil {
+				res.Body.Close()
+			}
+		}()
+		w.WriteHeader(202)
+	})
+	mux.HandleFunc("GET /results/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "result "+r.PathValue("id"))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestClient_DoAsyncCallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		callback  bool
+		wantPolls int64
+	}{
+		{name: "callback", callback: true, wantPolls: 1},
+		{name: "polling fallback", callback: false, wantPolls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls int64
+			svr := newCallbackServer(tt.callback, &polls)
+			defer svr.Close()
+
+			recv := &CallbackReceiver{Timeout: 20 * time.Millisecond}
+			if err := recv.Listen("127.0.0.1:0"); err != nil {
+				t.Fatal(err)
+			}
+			defer recv.Close()
+
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+				Poll:           &PollPolicy{Interval: time.Millisecond, MaxPolls: 5},
+			}
+			got := <-c.DoAsyncCallback(context.Background(), recv, &testCallbackRequest{id: "a"})
+			if got.Err != nil {
+				t.Fatalf("Client.DoAsyncCallback() error = %v", got.Err)
+			}
+			if string(got.Response.Data) != "result a" {
+				t.Errorf("Client.DoAsyncCallback() = %s, want result a", got.Response.Data)
+			}
+			if polls != tt.wantPolls || int64(got.Polls) != tt.wantPolls {
+				t.Errorf("Client.DoAsyncCallback() polls = %d, reported %d, want %d", polls, got.Polls, tt.wantPolls)
+			}
+		})
+	}
+}
+
+func TestCallbackReceiver_ServeHTTP(t *testing.T) {
+	recv := NewCallbackReceiver("https://example.com/hooks")
+	wait := recv.register("abc")
+	defer recv.unregister("abc")
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{name: "path", path: "/hooks/abc", want: http.StatusNoContent},
+		{name: "header", path: "/hooks", header: "abc", want: http.StatusNoContent},
+		{name: "unknown", path: "/hooks/xyz", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader([]byte("payload")))
+			if tt.header != "" {
+				req.Header.Set(CorrelationHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			recv.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("CallbackReceiver.ServeHTTP() code = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusNoContent {
+				if res := <-wait; string(res.Data) != "payload" {
+					t.Errorf("CallbackReceiver.ServeHTTP() delivered %s", res.Data)
+				}
+			}
+		})
+	}
+
+	if got := recv.CallbackURL("abc"); got != "https://example.com/hooks/abc" {
+		t.Errorf("CallbackReceiver.CallbackURL() = %s", got)
+	}
+}
@@ -0,0 +1,188 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint is the serializable state of a pagination in progress.
+type Checkpoint struct {
+	// Key identifies the pagination the Checkpoint belongs to.
+	Key string `json:"key"`
+
+	// State is the output of MarshalCheckpoint of the
+	// Checkpointer requesting the next page.
+	State json.RawMessage `json:"state"`
+
+	// Pages is the number of pages fetched before the Checkpoint.
+	Pages int `json:"pages"`
+
+	// Updated is the time the Checkpoint was saved.
+	Updated time.Time `json:"updated"`
+}
+
+// Checkpointer is a PaginatedRequest whose state can be saved in
+// a Checkpoint and later restored to resume the pagination.
+type Checkpointer interface {
+	PaginatedRequest
+
+	// MarshalCheckpoint returns the JSON encoded state of the request.
+	MarshalCheckpoint() ([]byte, error)
+
+	// UnmarshalCheckpoint returns a copy of the request
+	// with the state returned by MarshalCheckpoint.
+	UnmarshalCheckpoint(state []byte) (Checkpointer, error)
+}
+
+// CheckpointStore stores the Checkpoints of paginations in progress.
+type CheckpointStore interface {
+	// Load returns the Checkpoint of the key or nil if there is none.
+	Load(ctx context.Context, key string) (*Checkpoint, error)
+	Save(ctx context.Context, cp *Checkpoint) error
+	Delete(ctx context.Context, key string) error
+}
+
+// DoPaginationCheckpoint is like DoPagination but resumes from the
+// Checkpoint of the key in the store, if any, and saves a Checkpoint
+// after every fetched page.
+//
+// Only the pages fetched by the call are returned. When a request fails,
+// they are returned along with the error and the Checkpoint of the failed
+// page is kept in the store, so that a later call resumes from it. The
+// Checkpoint is deleted once the last page is fetched.
+func (c *Client) DoPaginationCheckpoint(ctx context.Context, key string, p Checkpointer, store CheckpointStore) ([]*Response, error) {
+	cp, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("jac: loading checkpoint: %w", err)
+	}
+	pages := 0
+	if cp != nil {
+		if p, err = p.UnmarshalCheckpoint(cp.State); err != nil {
+			return nil, fmt.Errorf("jac: restoring checkpoint: %w", err)
+		}
+		pages = cp.Pages
+	}
+
+	var responses []*Response
+	for {
+		res, err := c.Do(ctx, p)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, res)
+		pages++
+
+		next, done := p.Next(res)
+		if done {
+			if err := store.Delete(ctx, key); err != nil {
+				return responses, fmt.Errorf("jac: deleting checkpoint: %w", err)
+			}
+			return responses, nil
+		}
+		var ok bool
+		if p, ok = next.(Checkpointer); !ok {
+			return responses, fmt.Errorf("jac: next page request %T is not a Checkpointer", next)
+		}
+
+		state, err := p.MarshalCheckpoint()
+		if err != nil {
+			return responses, err
+		}
+		cp := &Checkpoint{Key: key, State: state, Pages: pages, Updated: time.Now()}
+		if err := store.Save(ctx, cp); err != nil {
+			return responses, fmt.Errorf("jac: saving checkpoint: %w", err)
+		}
+	}
+}
+
+// FileCheckpointStore is a CheckpointStore keeping every Checkpoint
+// in a JSON file of a directory. Files are replaced atomically.
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCheckpointStore returns a FileCheckpointStore using the directory,
+// which is created on the first save.
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{dir: dir}
+}
+
+// Load implements the CheckpointStore interface.
+func (f *FileCheckpointStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+
+	return &cp, nil
+}
+
+// Save implements the CheckpointStore interface.
+func (f *FileCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return err
+	}
+	path := f.path(cp.Key)
+	tmp, err := os.CreateTemp(f.dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete implements the CheckpointStore interface.
+func (f *FileCheckpointStore) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (f *FileCheckpointStore) path(key string) string {
+	return filepath.Join(f.dir, url.PathEscape(key)+".json")
+}
@@ -0,0 +1,104 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyPageServer serves pages 0 to 4 and fails page 3 while failing is set.
+func newFlakyPageServer(failing *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 3 && failing.Load() {
+			http.Error(w, "unavailable", http.StatusBadRequest)
+			return
+		}
+		if page < 4 {
+			w.Header().Set("Link", fmt.Sprintf(`</?q=all&page=%d>; rel="next"`, page+1))
+		}
+		fmt.Fprintf(w, `{"item":%d,"has_more":%t}`, page, page < 4)
+	}))
+}
+
+func TestClient_DoPaginationCheckpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Checkpointer
+	}{
+		{name: "page number", p: &PagePaginator{Request: &testItemsRequest{path: "/"}}},
+		{name: "link", p: &LinkPaginator{Request: &testItemsRequest{path: "/"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failing atomic.Bool
+			failing.Store(true)
+			svr := newFlakyPageServer(&failing)
+			defer svr.Close()
+
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+			dir := t.TempDir()
+			store := NewFileCheckpointStore(filepath.Join(dir, "checkpoints"))
+
+			got, err := c.DoPaginationCheckpoint(context.Background(), "nightly/items", tt.p, store)
+			if err == nil || len(got) != 3 {
+				t.Fatalf("Client.DoPaginationCheckpoint() = %d pages, %v, want 3 pages and an error", len(got), err)
+			}
+			cp, err := store.Load(context.Background(), "nightly/items")
+			if err != nil || cp == nil || cp.Pages != 3 {
+				t.Fatalf("FileCheckpointStore.Load() = %+v, %v, want 3 pages", cp, err)
+			}
+
+			failing.Store(false)
+			got, err = c.DoPaginationCheckpoint(context.Background(), "nightly/items", tt.p, store)
+			if err != nil {
+				t.Fatalf("Client.DoPaginationCheckpoint() resumed error = %v", err)
+			}
+			for i, res := range got {
+				var page struct{ Item int }
+				_ = json.Unmarshal(res.Data, &page)
+				if page.Item != i+3 {
+					t.Errorf("Client.DoPaginationCheckpoint() resumed page %d = %s", i, res.Data)
+				}
+			}
+			if len(got) != 2 {
+				t.Errorf("Client.DoPaginationCheckpoint() resumed pages = %d, want 2", len(got))
+			}
+			if cp, err := store.Load(context.Background(), "nightly/items"); cp != nil || err != nil {
+				t.Errorf("FileCheckpointStore.Load() after completion = %+v, %v", cp, err)
+			}
+			if entries, _ := os.ReadDir(filepath.Join(dir, "checkpoints")); len(entries) != 0 {
+				t.Errorf("FileCheckpointStore left %d files", len(entries))
+			}
+		})
+	}
+}
+
+func TestClient_DoPaginationPartial(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	svr := newFlakyPageServer(&failing)
+	defer svr.Close()
+
+	c := &Client{
+		BaseURL:        svr.URL,
+		DisableLogging: true,
+		Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+	}
+	got, err := c.DoPagination(context.Background(), &PagePaginator{Request: &testItemsRequest{path: "/"}})
+	if err == nil || len(got) != 3 {
+		t.Errorf("Client.DoPagination() = %d pages, %v, want 3 pages and an error", len(got), err)
+	}
+}
@@ -0,0 +1,109 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// defMaxURLLength is the default maximum length of the URL of a chunk.
+const defMaxURLLength = 2048
+
+// ChunkRequestFunc returns the Request looking up the IDs of a chunk.
+type ChunkRequestFunc func(ids []string) Request
+
+// ChunkOptions configures how DoChunked splits the IDs into chunks.
+type ChunkOptions struct {
+	// MaxIDs is the maximum number of IDs per chunk. Default is no limit.
+	MaxIDs int
+
+	// MaxURLLength is the maximum length of the URL of a chunk request,
+	// made of the BaseURL of the Client followed by the URI built with
+	// BuildURI. Default is 2048.
+	MaxURLLength int
+
+	// Concurrency is the maximum number of chunks requested
+	// concurrently. Default is no limit.
+	Concurrency int
+}
+
+// DoChunked splits the IDs into chunks respecting the limits of the options,
+// requests the chunks made by fn concurrently and returns the items extracted
+// from their responses, in the order of the chunks.
+//
+// The first failed chunk cancels the others and its error is returned.
+func DoChunked[T any](ctx context.Context, c *Client, ids []string, fn ChunkRequestFunc, extract Extractor[T], opts *ChunkOptions) ([]T, error) {
+	c.once.Do(c.init)
+	if opts == nil {
+		opts = &ChunkOptions{}
+	}
+	chunks, err := opts.chunk(c.BaseURL, ids, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]T, len(chunks))
+	err = forEach(ctx, len(chunks), opts.Concurrency, true, func(ctx context.Context, i int) error {
+		res, err := c.Do(ctx, fn(chunks[i]))
+		if err != nil {
+			return err
+		}
+		results[i], err = extract(res)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	for _, result := range results {
+		items = append(items, result...)
+	}
+
+	return items, nil
+}
+
+// chunk splits the IDs into the largest chunks respecting the limits.
+//
+// The URL length of a chunk grows by the escaped length of every added ID
+// plus the length of the separator measured with its first two IDs, and
+// the chunk is then checked against the length of its actual URL.
+func (o *ChunkOptions) chunk(baseURL string, ids []string, fn ChunkRequestFunc) ([][]string, error) {
+	maxLen := o.MaxURLLength
+	if maxLen <= 0 {
+		maxLen = defMaxURLLength
+	}
+	urlLen := func(chunk []string) int {
+		req := fn(chunk)
+		return len(baseURL + BuildURI(req.Path(), req.Query()))
+	}
+	canGrow := func(size int) bool {
+		return o.MaxIDs <= 0 || size < o.MaxIDs
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(ids); {
+		n := urlLen(ids[start : start+1])
+		if n > maxLen {
+			return nil, fmt.Errorf("jac: ID %q exceeds the maximum URL length of %d", ids[start], maxLen)
+		}
+		end := start + 1
+		if end < len(ids) && canGrow(1) {
+			sep := urlLen(ids[start:start+2]) - n - len(url.QueryEscape(ids[start+1]))
+			for end < len(ids) && canGrow(end-start) {
+				next := n + sep + len(url.QueryEscape(ids[end]))
+				if next > maxLen {
+					break
+				}
+				n, end = next, end+1
+			}
+			for end > start+1 && urlLen(ids[start:end]) > maxLen {
+				end--
+			}
+		}
+		chunks = append(chunks, ids[start:end:end])
+		start = end
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,147 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testIDsRequest struct {
+	*GetRequest
+	ids []string
+}
+
+func (t *testIDsRequest) Path() string {
+	return "/items"
+}
+
+func (t *testIDsRequest) Query() url.Values {
+	return url.Values{"ids": {strings.Join(t.ids, ",")}}
+}
+
+func newTestIDsRequest(ids []string) Request {
+	return &testIDsRequest{ids: ids}
+}
+
+type testPathIDsRequest struct {
+	*GetRequest
+	ids []string
+}
+
+func (t *testPathIDsRequest) Path() string {
+	return "/items/" + url.PathEscape(strings.Join(t.ids, "."))
+}
+
+func newTestPathIDsRequest(ids []string) Request {
+	return &testPathIDsRequest{ids: ids}
+}
+
+func TestDoChunked(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		if len(ids) > 10 || len(r.URL.String()) > 100 {
+			http.Error(w, "too many ids", http.StatusBadRequest)
+			return
+		}
+		items := make([]testItem, len(ids))
+		for i, id := range ids {
+			items[i].ID, _ = strconv.Atoi(id)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	}))
+	defer svr.Close()
+
+	ids := make([]string, 95)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i * 1000)
+	}
+	c := &Client{
+		BaseURL:        svr.URL,
+		DisableLogging: true,
+		Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+	}
+	got, err := DoChunked(context.Background(), c, ids, newTestIDsRequest, ItemsAt[testItem]("items"), &ChunkOptions{
+		MaxIDs:       10,
+		MaxURLLength: len(svr.URL) + 100,
+		Concurrency:  3,
+	})
+	if err != nil {
+		t.Fatalf("DoChunked() error = %v", err)
+	}
+	if len(got) != len(ids) {
+		t.Fatalf("DoChunked() items = %d, want %d", len(got), len(ids))
+	}
+	for i, item := range got {
+		if item.ID != i*1000 {
+			t.Errorf("DoChunked() item %d = %d, want %d", i, item.ID, i*1000)
+		}
+	}
+}
+
+func TestChunkOptions_chunk(t *testing.T) {
+	ids := []string{"1", "22", "333", "4444", "55555"}
+	base := "https://example.com"
+	tests := []struct {
+		name    string
+		opts    *ChunkOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "default limits", opts: &ChunkOptions{}, want: "[[1 22 333 4444 55555]]"},
+		{name: "max ids", opts: &ChunkOptions{MaxIDs: 2}, want: "[[1 22] [333 4444] [55555]]"},
+		// "/items?ids=" is 11 characters and a comma is escaped as %2C.
+		{name: "max url length", opts: &ChunkOptions{MaxURLLength: len(base) + 11 + 9}, want: "[[1 22] [333] [4444] [55555]]"},
+		{name: "id too long", opts: &ChunkOptions{MaxURLLength: len(base) + 11 + 4}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.chunk(base, ids, newTestIDsRequest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChunkOptions.chunk() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprint(got) != tt.want {
+				t.Errorf("ChunkOptions.chunk() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkOptions_chunk_linear(t *testing.T) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	var calls, size int
+	fn := func(ids []string) Request {
+		calls++
+		size += len(ids)
+		return newTestIDsRequest(ids)
+	}
+
+	chunks, err := (&ChunkOptions{MaxURLLength: 1 << 20}).chunk("https://example.com", ids, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || size > 3*len(ids) {
+		t.Errorf("ChunkOptions.chunk() = %d chunks with %d requests of %d IDs in total", len(chunks), calls, size)
+	}
+}
+
+func TestChunkOptions_chunk_escaped(t *testing.T) {
+	// The space of "c d" grows the path by more than estimated from "b".
+	ids := []string{"a", "b", "c d", "e"}
+	got, err := (&ChunkOptions{MaxURLLength: len("/items/") + 7}).chunk("", ids, newTestPathIDsRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[[a b] [c d e]]"; fmt.Sprint(got) != want {
+		t.Errorf("ChunkOptions.chunk() = %v, want %s", got, want)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +34,12 @@ type Retry struct {
 	Policy    RetryPolicy
 	Backoff   Backoff
 	MaxAmount int
+
+	// Check decides which failed attempts are retried, whether they
+	// received a Response or a transport error. When it is set, the Policy
+	// is ignored. Default retries Responses according to the Policy and
+	// transport errors according to RetryTransient.
+	Check RetryCheck
 }
 
 // Client is an API client capable of making requests to an HTTP based web API.
@@ -39,8 +48,19 @@ type Retry struct {
 type Client struct {
 	Name string
 	// BaseURL is the url appended to the path of outgoing requests.
+	// Default is the URL of the first of the Endpoints.
 	BaseURL string
 
+	// Endpoints are the base URLs serving the API, such as the regions of
+	// a provider. When set, every attempt of a request for the BaseURL is
+	// sent to the endpoint chosen by the Selector instead, and a retry
+	// moves to the next endpoint.
+	Endpoints []*Endpoint
+
+	// Selector chooses the endpoint of every attempt.
+	// Default is PrimaryFailover.
+	Selector EndpointSelector
+
 	// Headers are the default header values added to every outgoing request.
 	Headers http.Header
 
@@ -54,9 +74,21 @@ type Client struct {
 	// Retry policy used by the client.
 	Retry *Retry
 
+	// Poll is the PollPolicy used for async requests.
+	// Default is DefaultPollPolicy.
+	Poll *PollPolicy
+
+	// Jobs records the pending ResumableRequests of DoAsync, so that they can
+	// be resumed with ResumeAsync after a restart. Default is nil.
+	Jobs JobStore
+
 	// Limiter specifies the rate limit.
 	Limiter *rate.Limiter
 
+	// Budget is the shared rate limit the Limiter is nested inside.
+	// Every attempt of a request waits for both. Default is nil.
+	Budget *Budget
+
 	// TLSConfig is the custom TLSConfig the client will use.
 	//
 	// If the field is set, the Client will generate a new transport with
@@ -64,17 +96,41 @@ type Client struct {
 	// instead of the default client in jachttp package.
 	TLSConfig *tls.Config
 
+	// Cache stores the Responses of cacheable requests.
+	// Default is an InMemoryCache. It is ignored when CacheStore is set.
 	Cache Cache
 
+	// CacheStore is the context aware store used for caching Responses.
+	// When it is nil the Client uses the Cache through AdaptCache.
+	CacheStore CacheStore
+
+	// OnCacheError is called with the errors returned by the CacheStore
+	// other than ErrCacheMiss. Cache errors never fail a request.
+	OnCacheError func(key string, err error)
+
 	// IsSuccessful determines if a request should be considered successful or not.
 	IsSuccessful func(*http.Response) bool
 
+	// DisableCoalescing flag determines wheter concurrent idempotent requests
+	// with identical cache keys should be sent separately instead of sharing
+	// the Response of a single in-flight request.
+	// Cacheable requests are always coalesced. Default is false.
+	DisableCoalescing bool
+
 	hc     *http.Client
 	logger txnLogger
+	store  CacheStore
 
 	once sync.Once
 
-	mutexes sync.Map
+	flights flightGroup
+
+	// groupBudgets are the Budgets set by the ClientGroups of the Client.
+	groupBudgets []*Budget
+
+	// requests counts the in-flight requests, so that a Client
+	// replaced in a Registry is closed once they are done.
+	requests inflight
 }
 
 func defaultIsSuccessful(res *http.Response) bool {
@@ -85,6 +141,8 @@ func defaultIsSuccessful(res *http.Response) bool {
 func (c *Client) init() {
 	c.validate()
 	c.initRetry()
+	c.initEndpoints()
+	c.initPoll()
 	c.initLimiter()
 	c.initAuth()
 	c.initLogger()
@@ -96,6 +154,16 @@ func (c *Client) init() {
 }
 
 func (c *Client) validate() {
+	for _, e := range c.Endpoints {
+		if u, err := url.Parse(e.URL); err != nil || u.Host == "" {
+			panic("jac: invalid endpoint url: " + e.URL)
+		}
+		e.URL = strings.TrimSuffix(e.URL, "/")
+	}
+	if c.BaseURL == "" && len(c.Endpoints) > 0 {
+		c.BaseURL = c.Endpoints[0].URL
+	}
+
 	baseURL, err := url.Parse(c.BaseURL)
 	if err != nil {
 		panic("jac: invalid base url: " + c.BaseURL)
@@ -118,6 +186,18 @@ func (c *Client) initRetry() {
 	}
 }
 
+func (c *Client) initEndpoints() {
+	if len(c.Endpoints) > 0 && c.Selector == nil {
+		c.Selector = PrimaryFailover()
+	}
+}
+
+func (c *Client) initPoll() {
+	if c.Poll == nil {
+		c.Poll = DefaultPollPolicy
+	}
+}
+
 func (c *Client) initAuth() {
 	if c.Authorizer == nil {
 		c.Authorizer = zeroAuth
@@ -143,13 +223,18 @@ func (c *Client) initHTTPClient() {
 
 // Do makes an HTTP Request with the given context and returns the Response.
 func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	return c.doURI(ctx, req, BuildURI(req.Path(), req.Query()))
+}
+
+// doURI makes the Request for the URI relative to the BaseURL
+// instead of the path and query of the Request.
+func (c *Client) doURI(ctx context.Context, req Request, uri string) (*Response, error) {
 	c.once.Do(c.init)
 	method, ok := strToMethod[req.Method()]
 	if !ok {
 		return nil, fmt.Errorf("jac: invalid HTTP method %s", req.Method())
 	}
 
-	uri := BuildURI(req.Path(), req.Query())
 	body := req.Body()
 	header := req.Header()
 	if header == nil {
@@ -163,9 +248,18 @@ func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 		return nil, err
 	}
 
-	switch x := req.(type) {
-	case CacheRequest:
-		return c.doCache(x.CacheKey(), x.TTL(), httpRequest)
+	if x, ok := req.(CacheableRequest); ok {
+		var errTTLs map[int]time.Duration
+		if n, ok := x.(NegativeCacheRequest); ok {
+			errTTLs = n.ErrorTTLs()
+		}
+		return c.doCache(ctx, c.cacheKey(req, header, httpRequest), x.TTL(), errTTLs, httpRequest)
+	}
+
+	if method.IsIdempotent() && !c.DisableCoalescing {
+		return c.flights.do(ctx, c.cacheKey(req, header, httpRequest), func(ctx context.Context) (*Response, error) {
+			return c.do(httpRequest.WithContext(ctx))
+		})
 	}
 
 	return c.do(httpRequest)
@@ -181,28 +275,72 @@ func (c *Client) Get(ctx context.Context, u string) (*Response, error) {
 	return c.do(req)
 }
 
-func (c *Client) doCache(key string, dur time.Duration, req *http.Request) (*Response, error) {
-	// Wait for inflight requests with the same key to complete
-	mutexVal, ok := c.mutexes.LoadOrStore(key, &sync.Mutex{})
-	mutex := mutexVal.(*sync.Mutex)
-	mutex.Lock()
-	defer mutex.Unlock()
+// doCache returns the cached Response for the key if there is one.
+// Otherwise it makes the request, sharing it with the concurrent callers
+// using the same key, and caches the Response for the given duration.
+//
+// Unsuccessful Responses are cached for the duration of their status code
+// in errTTLs and are returned along with their StatusError when cached.
+func (c *Client) doCache(ctx context.Context, key string, dur time.Duration, errTTLs map[int]time.Duration, req *http.Request) (*Response, error) {
+	if response := c.cacheGet(ctx, key); response != nil {
+		return cachedResult(response, errTTLs)
+	}
+
+	return c.flights.do(ctx, key, func(ctx context.Context) (*Response, error) {
+		if response := c.cacheGet(ctx, key); response != nil {
+			return cachedResult(response, errTTLs)
+		}
+
+		response, err := c.do(req.WithContext(ctx))
+		if err != nil {
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) && errTTLs[statusErr.StatusCode] > 0 {
+				c.cacheSet(ctx, key, newCacheItem(response, errTTLs[statusErr.StatusCode]))
+			}
+			return response, err
+		}
+
+		c.cacheSet(ctx, key, newCacheItem(response, dur))
 
-	if response := c.Cache.Get(key); response != nil {
 		return response, nil
+	})
+}
+
+// cachedResult returns the cached Response along with
+// its StatusError if it is a negatively cached Response.
+func cachedResult(response *Response, errTTLs map[int]time.Duration) (*Response, error) {
+	if _, ok := errTTLs[response.StatusCode]; ok {
+		return response, newStatusError(response)
 	}
 
-	response, err := c.do(req)
+	return response, nil
+}
+
+// cacheGet returns the cached Response for the key or nil
+// if there is none or the CacheStore fails.
+func (c *Client) cacheGet(ctx context.Context, key string) *Response {
+	item, err := c.store.Get(ctx, key)
 	if err != nil {
-		return nil, err
+		if !errors.Is(err, ErrCacheMiss) {
+			c.cacheError(key, err)
+		}
+		return nil
 	}
 
-	c.Cache.Set(key, newCacheItem(response, dur))
-	if ok {
-		c.mutexes.Delete(key)
+	return item.Response
+}
+
+// cacheSet stores the item in the CacheStore.
+func (c *Client) cacheSet(ctx context.Context, key string, item *CacheItem) {
+	if err := c.store.Set(ctx, key, item); err != nil {
+		c.cacheError(key, err)
 	}
+}
 
-	return response, nil
+func (c *Client) cacheError(key string, err error) {
+	if c.OnCacheError != nil {
+		c.OnCacheError(key, err)
+	}
 }
 
 // createRequest returns an HTTP Request with the given message.
@@ -221,10 +359,9 @@ func (c *Client) createRequest(r *message) (*http.Request, error) {
 }
 
 func (c *Client) do(request *http.Request) (*Response, error) {
-	err := c.Limiter.Wait(request.Context())
-	if err != nil {
-		return nil, err
-	}
+	c.requests.add()
+	defer c.requests.done()
+
 	t, err := c.beginTxn(request)
 	if err != nil {
 		return nil, err
@@ -258,18 +395,74 @@ func (c *Client) prepRequest(req *http.Request) error {
 }
 
 func (c *Client) beginTxn(req *http.Request) (*transaction, error) {
-	t := &transaction{req: req, ret: c.Retry, isSuccessful: c.IsSuccessful}
+	t := &transaction{req: req, ret: c.Retry, isSuccessful: c.IsSuccessful, limit: c.waitLimits}
 	if c.hc != nil {
 		t.hc = c.hc
 	}
+	if len(c.Endpoints) > 0 {
+		t.router = &endpointRouter{endpoints: c.Endpoints, selector: c.Selector, base: c.BaseURL}
+		t.authorize = c.Authorizer.Authorize
+	}
 
 	return t, t.init()
 }
 
 func (c *Client) initCache() {
+	if c.CacheStore != nil {
+		c.store = c.CacheStore
+		return
+	}
 	if c.Cache == nil {
 		c.Cache = NewInMemoryCache()
 	}
+	c.store = AdaptCache(c.Cache)
+}
+
+// waitLimits blocks until the Limiter, the Budget
+// and the Budgets of the groups of the Client allow an attempt.
+func (c *Client) waitLimits(ctx context.Context) error {
+	b := &Budget{Name: c.Name, Limiter: c.Limiter, Parent: c.Budget}
+
+	return waitBudgets(ctx, append([]*Budget{b}, c.groupBudgets...)...)
+}
+
+// Close releases the resources held by the Client: it stops the evictor of
+// its Cache, closes its CacheStore and the idle connections of its own
+// transport. It must be called once the requests of the Client are done,
+// as a closed CacheStore may fail them.
+func (c *Client) Close() error {
+	return c.close(map[any]bool{})
+}
+
+// close closes the Client, skipping the Cache and CacheStore already closed,
+// so that the clients sharing them close them only once.
+func (c *Client) close(closed map[any]bool) error {
+	c.once.Do(c.init)
+	if c.hc != nil {
+		c.hc.CloseIdleConnections()
+	}
+	if x, ok := c.Cache.(interface{ StopEvictor() }); ok && markClosed(closed, c.Cache) {
+		x.StopEvictor()
+	}
+	if x, ok := c.CacheStore.(io.Closer); ok && markClosed(closed, c.CacheStore) {
+		return x.Close()
+	}
+
+	return nil
+}
+
+// markClosed records v as closed and reports whether it was not already.
+// Values that cannot be compared are always reported as not closed.
+func markClosed(closed map[any]bool, v any) bool {
+	if !reflect.TypeOf(v).Comparable() {
+		return true
+	}
+	if closed[v] {
+		return false
+	}
+	closed[v] = true
+
+	return true
 }
 
 func (c *Client) initLimiter() {
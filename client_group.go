@@ -2,10 +2,22 @@ package jac
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 )
 
+// ErrNoMatchingClient is returned by a ClientGroup when none
+// of its clients has a BaseURL matching the requested URL.
+var ErrNoMatchingClient = errors.New("jac: no matching client")
+
+// ClientGroup routes requests for absolute URLs to the Client whose
+// BaseURL matches the URL.
+//
+// A BaseURL matches when it has the same scheme and host as the URL and its
+// path is a prefix of the URL path made of whole path segments. When several
+// clients match, the one with the longest BaseURL path is chosen.
 type ClientGroup struct {
 	clients []*Client
 }
@@ -14,21 +26,116 @@ func NewClientGroup(clients ...*Client) *ClientGroup {
 	return &ClientGroup{clients: clients}
 }
 
+// Client returns the client of the group with the name or nil if there is none.
+func (c *ClientGroup) Client(name string) *Client {
+	for _, client := range c.clients {
+		if client.Name == name {
+			return client
+		}
+	}
+
+	return nil
+}
+
+// Get makes a GET request for the absolute URL with the matching Client.
 func (c *ClientGroup) Get(ctx context.Context, u string) (*Response, error) {
-	client := c.getClient(u)
-	if client == nil {
-		return nil, fmt.Errorf("jac: no matching client found for %s", u)
+	client, rel, err := c.route(u)
+	if err != nil {
+		return nil, err
 	}
 
-	return client.Get(context.Background(), u[len(client.BaseURL)+1:])
+	return client.Get(ctx, BuildURI(rel.EscapedPath(), rel.Query()))
 }
 
-func (c *ClientGroup) getClient(u string) *Client {
+// Do makes the Request with the Client matching the absolute URL.
+//
+// The path and query of the URL relative to the BaseURL of the Client
+// replace the path of the Request and are merged into its query.
+func (c *ClientGroup) Do(ctx context.Context, u string, req Request) (*Response, error) {
+	client, rel, err := c.route(u)
+	if err != nil {
+		return nil, err
+	}
+
+	query := cloneQuery(req.Query())
+	for k, vals := range rel.Query() {
+		for _, v := range vals {
+			query.Add(k, v)
+		}
+	}
+
+	return client.doURI(ctx, req, BuildURI(rel.EscapedPath(), query))
+}
+
+// route returns the Client matching the absolute URL
+// and the URL relative to the BaseURL of the Client.
+func (c *ClientGroup) route(raw string) (*Client, *url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !u.IsAbs() {
+		return nil, nil, fmt.Errorf("jac: %s is not an absolute URL", raw)
+	}
+
+	var (
+		match    *Client
+		matchLen = -1
+		rest     string
+	)
 	for _, client := range c.clients {
-		if strings.HasPrefix(u, client.BaseURL) {
-			return client
+		base, err := url.Parse(client.baseURL())
+		if err != nil || !sameOrigin(base, u) {
+			continue
+		}
+		basePath := strings.TrimSuffix(base.EscapedPath(), "/")
+		p, ok := strings.CutPrefix(u.EscapedPath(), basePath)
+		if !ok || (p != "" && !strings.HasPrefix(p, "/")) {
+			continue
 		}
+		if len(basePath) > matchLen {
+			match, matchLen, rest = client, len(basePath), p
+		}
+	}
+	if match == nil {
+		return nil, nil, c.noMatch(raw)
 	}
 
-	return nil
+	// The path stays escaped, so that escaped slashes and question
+	// marks of its segments are kept.
+	rel, err := url.Parse(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+	rel.RawQuery = u.RawQuery
+
+	return match, rel, nil
+}
+
+// noMatch returns the error listing the BaseURLs of the candidate clients.
+func (c *ClientGroup) noMatch(u string) error {
+	candidates := make([]string, len(c.clients))
+	for i, client := range c.clients {
+		candidates[i] = client.baseURL()
+	}
+
+	return fmt.Errorf("%w for %s among [%s]", ErrNoMatchingClient, u, strings.Join(candidates, ", "))
+}
+
+// sameOrigin reports whether the URLs have the same scheme and host,
+// ignoring case and default ports.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && normalizeHost(a) == normalizeHost(b)
+}
+
+// normalizeHost returns the lowercased host of the URL without the default
+// port of its scheme.
+func normalizeHost(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	scheme := strings.ToLower(u.Scheme)
+	if port := u.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+
+	return host
 }
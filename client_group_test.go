@@ -0,0 +1,148 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientGroup_route(t *testing.T) {
+	root := &Client{BaseURL: "https://api.x.com"}
+	v2 := &Client{BaseURL: "https://api.x.com/v2/"}
+	other := &Client{BaseURL: "http://API.other.com:80/base"}
+	regional := &Client{Endpoints: []*Endpoint{{URL: "https://eu.regional.com"}, {URL: "https://us.regional.com"}}}
+	g := NewClientGroup(root, v2, other, regional)
+
+	tests := []struct {
+		name     string
+		u        string
+		want     *Client
+		wantRest string
+		wantErr  bool
+	}{
+		{name: "root", u: "https://api.x.com/items?a=1", want: root, wantRest: "/items?a=1"},
+		{name: "escaped segment", u: "https://api.x.com/v2/a%2Fb%3Fc", want: v2, wantRest: "/a%2Fb%3Fc"},
+		{name: "longest prefix", u: "https://api.x.com/v2/items", want: v2, wantRest: "/items"},
+		{name: "longest prefix regardless of order", u: "https://api.x.com/v2", want: v2, wantRest: ""},
+		{name: "whole segments", u: "https://api.x.com/v20/items", want: root, wantRest: "/v20/items"},
+		{name: "default port and case", u: "http://api.other.com/base/x", want: other, wantRest: "/x"},
+		{name: "endpoints", u: "https://eu.regional.com/items", want: regional, wantRest: "/items"},
+		{name: "partial segment", u: "http://api.other.com/baseline", wantErr: true},
+		{name: "host prefix", u: "https://api.x.co/items", wantErr: true},
+		{name: "longer host", u: "https://api.x.com.evil.io/items", wantErr: true},
+		{name: "scheme", u: "http://api.x.com/items", wantErr: true},
+		{name: "relative", u: "/items", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := g.route(tt.u)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClientGroup.route() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want || rest.String() != tt.wantRest {
+				t.Errorf("ClientGroup.route() = %s, %q, want %s, %q", got.baseURL(), rest, tt.want.baseURL(), tt.wantRest)
+			}
+		})
+	}
+
+	_, _, err := g.route("https://api.x.co/items")
+	if !errors.Is(err, ErrNoMatchingClient) || !strings.Contains(err.Error(), "https://api.x.com/v2/") {
+		t.Errorf("ClientGroup.route() error = %v, want the candidates", err)
+	}
+}
+
+type testGroupRequest struct {
+	*GetRequest
+}
+
+func (t *testGroupRequest) Path() string {
+	return "/ignored"
+}
+
+func (t *testGroupRequest) Query() url.Values {
+	return url.Values{"fields": {"id"}}
+}
+
+func TestClientGroup_Do(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.String())
+	}))
+	defer svr.Close()
+
+	newClient := func(base string) *Client {
+		return &Client{
+			BaseURL:        base,
+			DisableLogging: true,
+			Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		}
+	}
+	g := NewClientGroup(newClient(svr.URL), newClient(svr.URL+"/v2"))
+
+	got, err := g.Get(context.Background(), svr.URL+"/v2/items?page=2")
+	if err != nil {
+		t.Fatalf("ClientGroup.Get() error = %v", err)
+	}
+	if string(got.Data) != "/v2/items?page=2" {
+		t.Errorf("ClientGroup.Get() requested %s", got.Data)
+	}
+
+	got, err = g.Do(context.Background(), svr.URL+"/v2/items?page=2", &testGroupRequest{})
+	if err != nil {
+		t.Fatalf("ClientGroup.Do() error = %v", err)
+	}
+	if string(got.Data) != "/v2/items?fields=id&page=2" {
+		t.Errorf("ClientGroup.Do() requested %s", got.Data)
+	}
+
+	got, err = g.Get(context.Background(), svr.URL+"/files/a%2Fb%3Fx=1")
+	if err != nil {
+		t.Fatalf("ClientGroup.Get() error = %v", err)
+	}
+	if string(got.Data) != "/files/a%2Fb%3Fx=1" {
+		t.Errorf("ClientGroup.Get() requested %s, want the escaped segment", got.Data)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Get(ctx, svr.URL+"/items"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ClientGroup.Get() with a cancelled context error = %v", err)
+	}
+}
+
+func TestClientGroup_Do_cache(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer svr.Close()
+
+	g := NewClientGroup(&Client{
+		BaseURL:        svr.URL,
+		DisableLogging: true,
+		Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+	})
+	for range 3 {
+		got, err := g.Do(context.Background(), svr.URL+"/items", &testTTLRequest{})
+		if err != nil {
+			t.Fatalf("ClientGroup.Do() error = %v", err)
+		}
+		if string(got.Data) != "/items" {
+			t.Errorf("ClientGroup.Do() requested %s", got.Data)
+		}
+	}
+
+	if hits != 1 {
+		t.Errorf("ClientGroup.Do() server hits = %d, want 1", hits)
+	}
+}
@@ -2,6 +2,7 @@ package jac
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -393,3 +394,75 @@ func TestClient_nooplogger(t *testing.T) {
 		return
 	}
 }
+
+type testNegativeCacheRequest struct {
+	*GetRequest
+	path string
+}
+
+func (t *testNegativeCacheRequest) Path() string {
+	return t.path
+}
+
+func (t *testNegativeCacheRequest) TTL() time.Duration {
+	return time.Hour
+}
+
+func (t *testNegativeCacheRequest) ErrorTTLs() map[int]time.Duration {
+	return map[int]time.Duration{404: time.Hour, 503: time.Hour}
+}
+
+func TestClient_DoNegativeCache(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(404)
+		case "/unavailable":
+			w.WriteHeader(503)
+		default:
+			w.WriteHeader(410)
+		}
+		fmt.Fprint(w, "not found")
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantHits int64
+	}{
+		{name: "cached", path: "/missing", wantCode: 404, wantHits: 1},
+		{name: "not cached", path: "/gone", wantCode: 410, wantHits: 3},
+		{name: "cached after retries", path: "/unavailable", wantCode: 503, wantHits: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits = 0
+			retry := &Retry{
+				Backoff:   LinearBackoff(time.Millisecond * 1),
+				Policy:    DefaultPolicy,
+				MaxAmount: 2,
+			}
+			c := &Client{BaseURL: svr.URL, DisableLogging: true, Retry: retry}
+			for i := 0; i < 3; i++ {
+				res, err := c.Do(context.Background(), &testNegativeCacheRequest{path: tt.path})
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("Client.Do() error = %v, want *StatusError", err)
+				}
+				if statusErr.StatusCode != tt.wantCode || string(statusErr.Body) != "not found" {
+					t.Errorf("Client.Do() error = %+v", statusErr)
+				}
+				if res == nil || res.StatusCode != tt.wantCode {
+					t.Errorf("Client.Do() = %+v", res)
+				}
+			}
+			if hits != tt.wantHits {
+				t.Errorf("Client.Do() server hits = %d, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
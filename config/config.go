@@ -0,0 +1,592 @@
+// Package config builds jac Clients from declarative YAML or JSON
+// configuration files.
+//
+// A configuration file defines named clients along with optional shared
+// rate-limit budgets:
+//
+//	budgets:
+//	  - name: vendor
+//	    rate: 10
+//	    burst: 5
+//	clients:
+//	  - name: orders
+//	    base_url: https://api.vendor.com/v2
+//	    headers:
+//	      Accept: application/json
+//	    auth:
+//	      type: basic
+//	      username: orders
+//	      password: {env: ORDERS_PASSWORD}
+//	    retry:
+//	      max_attempts: 3
+//	      status_codes: [429, 503]
+//	      backoff: {type: exponential, min: 1s, max: 30s}
+//	    limiter: {rate: 5, burst: 1}
+//	    budget: vendor
+//	    tls:
+//	      ca_file: /etc/vendor/ca.pem
+//	    cache:
+//	      eviction_interval: 1m
+//
+// Secrets are given inline, or read from an environment variable with
+// {env: NAME} or from a file with {file: path}.
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/darrae/jac"
+	"github.com/darrae/jac/auth"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of a configuration file.
+type Config struct {
+	Budgets []BudgetConfig `yaml:"budgets"`
+	Clients []ClientConfig `yaml:"clients"`
+}
+
+// BudgetConfig defines a jac.Budget shared by the clients referring to it.
+type BudgetConfig struct {
+	Name string `yaml:"name"`
+
+	// Rate is the number of events allowed per second.
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+
+	// Parent is the name of the enclosing budget, which
+	// must be defined before the budget.
+	Parent string `yaml:"parent"`
+}
+
+// ClientConfig defines a named jac.Client.
+type ClientConfig struct {
+	Name    string            `yaml:"name"`
+	BaseURL string            `yaml:"base_url"`
+	Headers map[string]string `yaml:"headers"`
+	Auth    *AuthConfig       `yaml:"auth"`
+	Retry   *RetryConfig      `yaml:"retry"`
+	Limiter *LimiterConfig    `yaml:"limiter"`
+	Budget  string            `yaml:"budget"`
+	TLS     *TLSConfig        `yaml:"tls"`
+	Cache   *CacheConfig      `yaml:"cache"`
+}
+
+// AuthConfig defines the Authorizer of a client. Type is one of basic,
+// api_key, oauth, oauth2 and bearer_api, and determines the fields in use.
+type AuthConfig struct {
+	Type string `yaml:"type"`
+
+	// Username and Password are used by basic.
+	Username string `yaml:"username"`
+	Password Secret `yaml:"password"`
+
+	// Key, Value and In, either header or query, are used by api_key.
+	Key   string `yaml:"key"`
+	Value Secret `yaml:"value"`
+	In    string `yaml:"in"`
+
+	// ConsumerKey and ConsumerSecret are used by oauth.
+	ConsumerKey    string `yaml:"consumer_key"`
+	ConsumerSecret Secret `yaml:"consumer_secret"`
+
+	// ClientID, ClientSecret, RefreshToken, GrantType, TokenURL and Body are
+	// used by oauth2. bearer_api uses ClientSecret, RefreshToken and TokenURL
+	// along with the header names of the secrets.
+	ClientID           string `yaml:"client_id"`
+	ClientSecret       Secret `yaml:"client_secret"`
+	RefreshToken       Secret `yaml:"refresh_token"`
+	GrantType          string `yaml:"grant_type"`
+	TokenURL           string `yaml:"token_url"`
+	Body               bool   `yaml:"body"`
+	ClientSecretHeader string `yaml:"client_secret_header"`
+	RefreshTokenHeader string `yaml:"refresh_token_header"`
+}
+
+// RetryConfig defines the retry logic of a client.
+type RetryConfig struct {
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// StatusCodes are the retried status codes.
+	// Default is the codes of jac.DefaultPolicy.
+	StatusCodes []int `yaml:"status_codes"`
+
+	// IdempotentOnly restricts retries to idempotent requests.
+	IdempotentOnly bool `yaml:"idempotent_only"`
+
+	Backoff *BackoffConfig `yaml:"backoff"`
+}
+
+// BackoffConfig defines a jac.Backoff. Type is either exponential,
+// which uses Min and Max, or linear, which uses Min as its base.
+type BackoffConfig struct {
+	Type string        `yaml:"type"`
+	Min  time.Duration `yaml:"min"`
+	Max  time.Duration `yaml:"max"`
+}
+
+// LimiterConfig defines the rate limit of a client in events per second.
+type LimiterConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// TLSConfig defines the TLS configuration of a client.
+type TLSConfig struct {
+	// CAFile is the PEM encoded file of the trusted certificate authorities.
+	CAFile string `yaml:"ca_file"`
+
+	// CertFile and KeyFile are the PEM encoded files
+	// of the client certificate and its private key.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	ServerName string `yaml:"server_name"`
+}
+
+// CacheConfig defines the in-memory cache of a client.
+type CacheConfig struct {
+	EvictionInterval time.Duration `yaml:"eviction_interval"`
+
+	// Encryption encrypts the cached Responses when set.
+	Encryption *EncryptionConfig `yaml:"encryption"`
+}
+
+// EncryptionConfig defines the jac.Keyring of an encrypted cache.
+// Keys are base64 encoded AES keys.
+type EncryptionConfig struct {
+	Primary string            `yaml:"primary"`
+	Keys    map[string]Secret `yaml:"keys"`
+}
+
+// Secret is a configuration value given inline or read from an
+// environment variable or a file.
+type Secret struct {
+	Value string `yaml:"-"`
+	Env   string `yaml:"env"`
+	File  string `yaml:"file"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. A scalar
+// is an inline value, otherwise one of env or file must be set.
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&s.Value)
+	}
+
+	type plain Secret
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	if (s.Env != "") == (s.File != "") {
+		return fmt.Errorf("line %d: secret needs exactly one of env and file", node.Line)
+	}
+
+	return nil
+}
+
+// resolve returns the value of the secret.
+func (s Secret) resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return v, nil
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	return s.Value, nil
+}
+
+// Load reads the configuration file at the path and builds its ClientGroup.
+func Load(path string) (*jac.ClientGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return cfg.Build()
+}
+
+// Parse decodes a YAML or JSON configuration. Unknown fields are rejected.
+func Parse(data []byte) (*Config, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Build validates the configuration and builds the ClientGroup of its
+// clients. All the validation errors are reported at once.
+func (c *Config) Build() (*jac.ClientGroup, error) {
+	var errs []error
+	report := func(field string, err error) {
+		errs = append(errs, fmt.Errorf("config: %s: %w", field, err))
+	}
+
+	budgets := jac.NewBudgetRegistry()
+	for i, b := range c.Budgets {
+		field := fmt.Sprintf("budgets[%d]", i)
+		if b.Name == "" {
+			report(field+".name", errors.New("missing"))
+			continue
+		}
+		if b.Rate <= 0 || b.Burst <= 0 {
+			report(field, errors.New("rate and burst must be positive"))
+			continue
+		}
+		if _, err := budgets.Define(b.Name, rate.Limit(b.Rate), b.Burst, b.Parent); err != nil {
+			report(field, err)
+		}
+	}
+
+	names := map[string]bool{}
+	clients := make([]*jac.Client, 0, len(c.Clients))
+	newCaches := make([]func() jac.Cache, 0, len(c.Clients))
+	for i := range c.Clients {
+		cc := &c.Clients[i]
+		field := fmt.Sprintf("clients[%d]", i)
+		if cc.Name != "" {
+			field += " (" + cc.Name + ")"
+		}
+		if names[cc.Name] {
+			report(field+".name", errors.New("duplicate"))
+		}
+		names[cc.Name] = true
+
+		client, newCache, clientErrs := cc.build(budgets)
+		for _, err := range clientErrs {
+			report(field+"."+err.field, err.err)
+		}
+		clients = append(clients, client)
+		newCaches = append(newCaches, newCache)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	// Caches start their evictor, so they are only
+	// created once the whole configuration is valid.
+	for i, newCache := range newCaches {
+		if newCache != nil {
+			clients[i].Cache = newCache()
+		}
+	}
+
+	return jac.NewClientGroup(clients...), nil
+}
+
+// fieldError is a validation error of a field of a client.
+type fieldError struct {
+	field string
+	err   error
+}
+
+// build returns the client and the constructor of its cache, if any,
+// along with the errors of its fields.
+func (cc *ClientConfig) build(budgets *jac.BudgetRegistry) (*jac.Client, func() jac.Cache, []fieldError) {
+	var errs []fieldError
+	report := func(field string, err error) {
+		errs = append(errs, fieldError{field: field, err: err})
+	}
+
+	client := &jac.Client{Name: cc.Name, BaseURL: cc.BaseURL}
+	if cc.Name == "" {
+		report("name", errors.New("missing"))
+	}
+	if u, err := url.Parse(cc.BaseURL); err != nil {
+		report("base_url", err)
+	} else if u.Scheme == "" || u.Host == "" {
+		report("base_url", fmt.Errorf("%q is not an absolute URL", cc.BaseURL))
+	}
+
+	if len(cc.Headers) > 0 {
+		client.Headers = http.Header{}
+		for k, v := range cc.Headers {
+			client.Headers.Set(k, v)
+		}
+	}
+
+	var err error
+	if cc.Auth != nil {
+		var authErrs []fieldError
+		client.Authorizer, authErrs = cc.Auth.build()
+		for _, e := range authErrs {
+			report("auth."+e.field, e.err)
+		}
+	}
+	if cc.Retry != nil {
+		if client.Retry, err = cc.Retry.build(); err != nil {
+			report("retry", err)
+		}
+	}
+	if cc.Limiter != nil {
+		if cc.Limiter.Rate <= 0 || cc.Limiter.Burst <= 0 {
+			report("limiter", errors.New("rate and burst must be positive"))
+		}
+		client.Limiter = rate.NewLimiter(rate.Limit(cc.Limiter.Rate), cc.Limiter.Burst)
+	}
+	if cc.Budget != "" {
+		if client.Budget = budgets.Get(cc.Budget); client.Budget == nil {
+			report("budget", fmt.Errorf("budget %s is not defined", cc.Budget))
+		}
+	}
+	if cc.TLS != nil {
+		if client.TLSConfig, err = cc.TLS.build(); err != nil {
+			report("tls", err)
+		}
+	}
+	var newCache func() jac.Cache
+	if cc.Cache != nil {
+		if newCache, err = cc.Cache.build(); err != nil {
+			report("cache", err)
+		}
+	}
+
+	return client, newCache, errs
+}
+
+// secrets resolves the named secrets, joining the errors of those that fail.
+func secrets(named map[string]Secret) (map[string]string, error) {
+	values := make(map[string]string, len(named))
+	var errs []error
+	for name, s := range named {
+		v, err := s.resolve()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		values[name] = v
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// build returns the Authorizer along with the errors of the fields
+// of the auth configuration, all of which are reported.
+func (a *AuthConfig) build() (jac.Authorizer, []fieldError) {
+	type field struct {
+		name  string
+		value string
+	}
+	var (
+		errs     []fieldError
+		required []field
+		named    = map[string]Secret{}
+		optional = map[string]bool{}
+	)
+	switch a.Type {
+	case "basic":
+		required = []field{{"username", a.Username}}
+		named["password"] = a.Password
+	case "api_key":
+		if a.In != "header" && a.In != "query" {
+			errs = append(errs, fieldError{field: "in", err: fmt.Errorf("must be header or query, not %q", a.In)})
+		}
+		required = []field{{"key", a.Key}}
+		named["value"] = a.Value
+	case "oauth":
+		required = []field{{"consumer_key", a.ConsumerKey}}
+		named["consumer_secret"] = a.ConsumerSecret
+	case "oauth2":
+		required = []field{{"client_id", a.ClientID}, {"token_url", a.TokenURL}}
+		named["client_secret"] = a.ClientSecret
+		named["refresh_token"] = a.RefreshToken
+		optional["refresh_token"] = true
+	case "bearer_api":
+		required = []field{
+			{"token_url", a.TokenURL},
+			{"client_secret_header", a.ClientSecretHeader},
+			{"refresh_token_header", a.RefreshTokenHeader},
+		}
+		named["client_secret"] = a.ClientSecret
+		named["refresh_token"] = a.RefreshToken
+	default:
+		return nil, []fieldError{{field: "type", err: fmt.Errorf("unknown type %q", a.Type)}}
+	}
+
+	for _, f := range required {
+		if f.value == "" {
+			errs = append(errs, fieldError{field: f.name, err: fmt.Errorf("missing, required by %s", a.Type)})
+		}
+	}
+	values := make(map[string]string, len(named))
+	for _, name := range slices.Sorted(maps.Keys(named)) {
+		v, err := named[name].resolve()
+		switch {
+		case err != nil:
+			errs = append(errs, fieldError{field: name, err: err})
+		case v == "" && !optional[name]:
+			errs = append(errs, fieldError{field: name, err: fmt.Errorf("missing, required by %s", a.Type)})
+		}
+		values[name] = v
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	switch a.Type {
+	case "basic":
+		return auth.NewBasic(a.Username, values["password"]), nil
+	case "api_key":
+		in := auth.InHeader
+		if a.In == "query" {
+			in = auth.InQuery
+		}
+		return auth.NewAPIKey(a.Key, values["value"], in), nil
+	case "oauth":
+		return auth.NewOAuth(a.ConsumerKey, values["consumer_secret"]), nil
+	case "oauth2":
+		return auth.NewOAuth2(auth.OAuth2Config{
+			ClientID:     a.ClientID,
+			ClientSecret: values["client_secret"],
+			RefreshToken: values["refresh_token"],
+			GrantType:    a.GrantType,
+			IsBody:       a.Body,
+		}, a.TokenURL), nil
+	default:
+		return auth.NewBearerAPI(
+			auth.ClientSecret{HeaderKey: a.ClientSecretHeader, Value: values["client_secret"]},
+			auth.RefreshToken{HeaderKey: a.RefreshTokenHeader, Value: values["refresh_token"]},
+			a.TokenURL,
+		), nil
+	}
+}
+
+func (r *RetryConfig) build() (*jac.Retry, error) {
+	var errs []error
+	if r.MaxAttempts < 1 {
+		errs = append(errs, errors.New("max_attempts must be at least 1"))
+	}
+
+	codes := r.StatusCodes
+	if len(codes) == 0 {
+		// The codes of jac.DefaultPolicy.
+		codes = []int{429, 500, 502, 503, 504}
+	}
+	for _, code := range codes {
+		if code < 100 || code > 599 {
+			errs = append(errs, fmt.Errorf("invalid status code %d", code))
+		}
+	}
+	policy := jac.RetryOn(codes)
+	if r.IdempotentOnly {
+		policy = jac.RetryIdempotentsOn(codes)
+	}
+
+	backoff := jac.DefaultBackoff
+	if r.Backoff != nil {
+		var err error
+		if backoff, err = r.Backoff.build(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return &jac.Retry{Policy: policy, Backoff: backoff, MaxAmount: r.MaxAttempts}, errors.Join(errs...)
+}
+
+func (b *BackoffConfig) build() (jac.Backoff, error) {
+	switch b.Type {
+	case "exponential":
+		if b.Min <= 0 || b.Max < b.Min {
+			return nil, errors.New("exponential backoff needs 0 < min <= max")
+		}
+		return jac.ExponentialBackoff(b.Min, b.Max), nil
+	case "linear":
+		if b.Min <= 0 {
+			return nil, errors.New("linear backoff needs a positive min")
+		}
+		return jac.LinearBackoff(b.Min), nil
+	}
+
+	return nil, fmt.Errorf("unknown backoff type %q", b.Type)
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	conf := &tls.Config{ServerName: t.ServerName, MinVersion: tls.VersionTLS12}
+	var errs []error
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			conf.RootCAs = x509.NewCertPool()
+			if !conf.RootCAs.AppendCertsFromPEM(pem) {
+				errs = append(errs, fmt.Errorf("no certificate found in %s", t.CAFile))
+			}
+		}
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, errors.New("cert_file and key_file must be set together"))
+	} else if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			conf.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return conf, errors.Join(errs...)
+}
+
+// build validates the cache configuration and returns the constructor of the cache.
+func (c *CacheConfig) build() (func() jac.Cache, error) {
+	var opts []jac.InMemoryCacheOption
+	if c.EvictionInterval > 0 {
+		opts = append(opts, jac.WithEvictionInterval(c.EvictionInterval))
+	}
+	if c.Encryption == nil {
+		return func() jac.Cache { return jac.NewInMemoryCache(opts...) }, nil
+	}
+
+	values, err := secrets(c.Encryption.Keys)
+	if err != nil {
+		return nil, err
+	}
+	keys := jac.Keyring{Primary: c.Encryption.Primary, Keys: map[string][]byte{}}
+	for id, v := range values {
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		keys.Keys[id] = key
+	}
+	if _, err := jac.NewEncryptedCache(nil, keys); err != nil {
+		return nil, err
+	}
+
+	return func() jac.Cache {
+		cache, _ := jac.NewEncryptedCache(jac.NewInMemoryCache(opts...), keys)
+		return cache
+	}, nil
+}
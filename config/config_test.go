@@ -0,0 +1,219 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darrae/jac"
+)
+
+type testRequest struct {
+	*jac.GetRequest
+}
+
+func (t *testRequest) Path() string {
+	return "/items"
+}
+
+func TestLoad(t *testing.T) {
+	svr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		fmt.Fprintf(w, "%s:%s %s", user, pass, r.Header.Get("Accept"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svr.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "cache.key")
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_ORDERS_PASSWORD", "s3cret")
+
+	path := filepath.Join(dir, "clients.yaml")
+	data := fmt.Sprintf(`
+budgets:
+  - {name: global, rate: 100, burst: 10}
+  - {name: vendor, rate: 10, burst: 1, parent: global}
+clients:
+  - name: orders
+    base_url: %s/v1
+    headers:
+      Accept: application/json
+    auth:
+      type: basic
+      username: orders
+      password: {env: TEST_ORDERS_PASSWORD}
+    retry:
+      max_attempts: 2
+      status_codes: [503]
+      backoff: {type: linear, min: 10ms}
+    limiter: {rate: 5, burst: 1}
+    budget: vendor
+    tls:
+      ca_file: %s
+    cache:
+      eviction_interval: 1m
+      encryption:
+        primary: k1
+        keys:
+          k1: {file: %s}
+  - name: billing
+    base_url: https://billing.example.com
+    auth: {type: api_key, key: X-API-Key, value: inline, in: header}
+`, svr.URL, caFile, keyFile)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	orders := g.Client("orders")
+	if orders == nil || g.Client("billing") == nil {
+		t.Fatalf("Load() did not build the named clients")
+	}
+	if orders.Budget == nil || orders.Budget.Name != "vendor" || orders.Budget.Parent.Name != "global" {
+		t.Errorf("Load() budget = %+v", orders.Budget)
+	}
+	if orders.Retry.MaxAmount != 2 || orders.Retry.Backoff(1) != 10*time.Millisecond {
+		t.Errorf("Load() retry = %+v", orders.Retry)
+	}
+	if _, ok := orders.Cache.(*jac.EncryptedCache); !ok {
+		t.Errorf("Load() cache = %T, want an EncryptedCache", orders.Cache)
+	}
+
+	orders.DisableLogging = true
+	res, err := orders.Do(context.Background(), &testRequest{})
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	if string(res.Data) != "orders:s3cret application/json" {
+		t.Errorf("Client.Do() = %s", res.Data)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	cfg, err := Parse([]byte(`{"clients": [{"name": "a", "base_url": "https://a.example.com", "auth": {"type": "oauth", "consumer_key": "k", "consumer_secret": {"env": "X"}}}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.Clients[0].Auth.ConsumerSecret.Env != "X" {
+		t.Errorf("Parse() secret = %+v", cfg.Clients[0].Auth.ConsumerSecret)
+	}
+
+	if _, err := Parse([]byte(`{"clients": [{"name": "a", "base_urll": "x"}]}`)); err == nil {
+		t.Errorf("Parse() unknown field error = nil")
+	}
+	if _, err := Parse([]byte(`{"clients": [{"auth": {"type": "basic", "password": {"env": "X", "file": "y"}}}]}`)); err == nil {
+		t.Errorf("Parse() ambiguous secret error = nil")
+	}
+}
+
+func TestConfig_BuildReportsAllErrors(t *testing.T) {
+	cfg, err := Parse([]byte(`
+budgets:
+  - {name: vendor, rate: 10, burst: 1, parent: missing}
+clients:
+  - name: a
+    base_url: /relative
+    auth: {type: basic, username: a, password: {env: TEST_UNSET_SECRET}}
+    retry:
+      max_attempts: 0
+      backoff: {type: quadratic}
+  - name: a
+    base_url: https://a.example.com
+    limiter: {rate: 0, burst: 1}
+    auth: {type: bearer_api, client_secret_header: X-Secret}
+    budget: unknown
+    tls: {cert_file: cert.pem}
+    cache:
+      encryption: {primary: k2, keys: {k1: "not base64!"}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cfg.Build()
+	if err == nil {
+		t.Fatal("Config.Build() error = nil")
+	}
+	for _, want := range []string{
+		"budgets[0]",
+		"clients[0] (a).base_url",
+		"clients[0] (a).auth.password: environment variable TEST_UNSET_SECRET",
+		"max_attempts",
+		"quadratic",
+		"clients[1] (a).name: duplicate",
+		"clients[1] (a).limiter",
+		"clients[1] (a).auth.token_url: missing",
+		"clients[1] (a).auth.refresh_token_header: missing",
+		"budget unknown is not defined",
+		"cert_file and key_file",
+		"clients[1] (a).cache",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Config.Build() error does not report %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestAuthConfig_build(t *testing.T) {
+	tests := []struct {
+		name string
+		auth AuthConfig
+		want []string
+	}{
+		{
+			name: "basic without password",
+			auth: AuthConfig{Type: "basic", Username: "u"},
+			want: []string{"password"},
+		},
+		{
+			name: "api_key without value",
+			auth: AuthConfig{Type: "api_key", Key: "X-API-Key", In: "header"},
+			want: []string{"value"},
+		},
+		{
+			name: "oauth without consumer_secret",
+			auth: AuthConfig{Type: "oauth", ConsumerKey: "k"},
+			want: []string{"consumer_secret"},
+		},
+		{
+			name: "oauth2 without refresh_token",
+			auth: AuthConfig{Type: "oauth2", ClientID: "id", TokenURL: "https://a.example.com/token", ClientSecret: Secret{Value: "s"}},
+		},
+		{
+			name: "bearer_api without anything",
+			auth: AuthConfig{Type: "bearer_api"},
+			want: []string{"token_url", "client_secret_header", "refresh_token_header", "client_secret", "refresh_token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := tt.auth.build()
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.field)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("AuthConfig.build() missing fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
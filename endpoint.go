@@ -0,0 +1,224 @@
+package jac
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defEndpointFailures is the number of consecutive failed
+	// attempts after which an endpoint is considered unhealthy.
+	defEndpointFailures = 3
+
+	// defEndpointCooldown is the duration after its last failure
+	// an unhealthy endpoint is considered healthy again.
+	defEndpointCooldown = 30 * time.Second
+
+	// latencyDecay is the weight of the latest latency
+	// in the moving average of an endpoint.
+	latencyDecay = 0.3
+)
+
+// Endpoint is a base URL serving the API of a Client, such as a region of
+// a provider. Its health is tracked passively from the outcome of the
+// attempts sent to it.
+type Endpoint struct {
+	// URL is the base URL of the endpoint.
+	URL string
+
+	// Weight is the relative share of the requests sent to the endpoint
+	// by the Weighted selector. Default is 1.
+	Weight int
+
+	mu          sync.Mutex
+	failures    int
+	lastFailure time.Time
+	latency     time.Duration
+}
+
+// Healthy reports whether the endpoint is healthy. An endpoint is unhealthy
+// for 30 seconds after 3 consecutive failed attempts, where failures are
+// transport errors, 429 Too Many Requests and 5xx responses.
+func (e *Endpoint) Healthy() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.failures < defEndpointFailures || time.Since(e.lastFailure) >= defEndpointCooldown
+}
+
+// Latency returns the moving average of the latency of the
+// successful attempts, or 0 if there has been none.
+func (e *Endpoint) Latency() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.latency
+}
+
+// record updates the health of the endpoint with the outcome of an attempt.
+func (e *Endpoint) record(res *http.Response, err error, latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil || res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		e.failures++
+		e.lastFailure = time.Now()
+		return
+	}
+
+	e.failures = 0
+	if e.latency == 0 {
+		e.latency = latency
+		return
+	}
+	e.latency = time.Duration(latencyDecay*float64(latency) + (1-latencyDecay)*float64(e.latency))
+}
+
+func (e *Endpoint) weight() int {
+	if e.Weight <= 0 {
+		return 1
+	}
+
+	return e.Weight
+}
+
+// EndpointSelector chooses the endpoint of an attempt. tried holds the
+// endpoints of the previous attempts of the same request, in order, so
+// that a retry can move to another endpoint.
+type EndpointSelector func(endpoints []*Endpoint, tried []*Endpoint) *Endpoint
+
+// PrimaryFailover is an EndpointSelector sending every request to the first
+// healthy endpoint, in order. Retries move to the next endpoint.
+func PrimaryFailover() EndpointSelector {
+	return func(endpoints []*Endpoint, tried []*Endpoint) *Endpoint {
+		return candidates(endpoints, tried)[0]
+	}
+}
+
+// RoundRobin is an EndpointSelector spreading the requests over the healthy
+// endpoints in turn. Retries move to the next endpoint.
+func RoundRobin() EndpointSelector {
+	var n atomic.Uint64
+	return func(endpoints []*Endpoint, tried []*Endpoint) *Endpoint {
+		c := candidates(endpoints, tried)
+		return c[(n.Add(1)-1)%uint64(len(c))]
+	}
+}
+
+// Weighted is an EndpointSelector spreading the requests randomly over the
+// healthy endpoints in proportion to their Weight. Retries move to another
+// endpoint.
+func Weighted() EndpointSelector {
+	return func(endpoints []*Endpoint, tried []*Endpoint) *Endpoint {
+		c := candidates(endpoints, tried)
+		var total int
+		for _, e := range c {
+			total += e.weight()
+		}
+		r := rand.IntN(total)
+		for _, e := range c {
+			if r -= e.weight(); r < 0 {
+				return e
+			}
+		}
+
+		return c[len(c)-1]
+	}
+}
+
+// LeastLatency is an EndpointSelector sending every request to the healthy
+// endpoint with the lowest average latency. Endpoints without a measured
+// latency are tried first. Retries move to the next fastest endpoint.
+func LeastLatency() EndpointSelector {
+	return func(endpoints []*Endpoint, tried []*Endpoint) *Endpoint {
+		c := candidates(endpoints, tried)
+		best := c[0]
+		for _, e := range c[1:] {
+			if e.Latency() < best.Latency() {
+				best = e
+			}
+		}
+
+		return best
+	}
+}
+
+// candidates returns the healthy endpoints that were not tried yet, in order.
+// It falls back to the endpoints that were not tried, then to the healthy
+// endpoints and finally to all the endpoints, so that it is never empty.
+func candidates(endpoints []*Endpoint, tried []*Endpoint) []*Endpoint {
+	isTried := func(e *Endpoint) bool {
+		for _, t := range tried {
+			if t == e {
+				return true
+			}
+		}
+		return false
+	}
+
+	var untried, healthy, both []*Endpoint
+	for _, e := range endpoints {
+		ok, fresh := e.Healthy(), !isTried(e)
+		if ok {
+			healthy = append(healthy, e)
+		}
+		if fresh {
+			untried = append(untried, e)
+		}
+		if ok && fresh {
+			both = append(both, e)
+		}
+	}
+
+	for _, c := range [][]*Endpoint{both, untried, healthy} {
+		if len(c) > 0 {
+			return c
+		}
+	}
+
+	return endpoints
+}
+
+// baseURL returns the BaseURL of the Client or, before it is initialized,
+// the URL of the first of its Endpoints when the BaseURL is not set.
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" && len(c.Endpoints) > 0 {
+		return c.Endpoints[0].URL
+	}
+
+	return c.BaseURL
+}
+
+// endpointRouter routes the attempts of a transaction to the endpoints.
+type endpointRouter struct {
+	endpoints []*Endpoint
+	selector  EndpointSelector
+	base      string
+	tried     []*Endpoint
+}
+
+// route points the request to the endpoint chosen for the next attempt and
+// returns it. Requests that do not target the base URL are left as is.
+func (r *endpointRouter) route(req *http.Request) *Endpoint {
+	uri, ok := strings.CutPrefix(req.URL.String(), r.base)
+	if !ok || (uri != "" && uri[0] != '/' && uri[0] != '?') {
+		return nil
+	}
+
+	e := r.selector(r.endpoints, r.tried)
+	u, err := url.Parse(e.URL + uri)
+	if err != nil {
+		return nil
+	}
+	r.tried = append(r.tried, e)
+	r.base = e.URL
+	req.URL = u
+	req.Host = ""
+
+	return e
+}
@@ -0,0 +1,201 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEndpointServer struct {
+	*httptest.Server
+	hits atomic.Int64
+}
+
+func newTestEndpointServer(name string, status int) *testEndpointServer {
+	s := &testEndpointServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		w.WriteHeader(status)
+		fmt.Fprint(w, name+" "+r.URL.RequestURI())
+	}))
+
+	return s
+}
+
+func newEndpointClient(selector EndpointSelector, urls ...string) *Client {
+	endpoints := make([]*Endpoint, len(urls))
+	for i, u := range urls {
+		endpoints[i] = &Endpoint{URL: u + "/api/"}
+	}
+
+	return &Client{
+		Endpoints:         endpoints,
+		Selector:          selector,
+		DisableLogging:    true,
+		DisableCoalescing: true,
+		Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 2},
+	}
+}
+
+func TestClient_DoEndpointsFailover(t *testing.T) {
+	primary := newTestEndpointServer("primary", http.StatusServiceUnavailable)
+	defer primary.Close()
+	secondary := newTestEndpointServer("secondary", http.StatusOK)
+	defer secondary.Close()
+
+	c := newEndpointClient(nil, primary.URL, secondary.URL)
+	for i := 0; i < 5; i++ {
+		got, err := c.Do(context.Background(), &testItemsRequest{path: "/items"})
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		if string(got.Data) != "secondary /api/items?q=all" {
+			t.Errorf("Client.Do() = %s, want secondary /api/items?q=all", got.Data)
+		}
+	}
+
+	// The primary is skipped once it failed the maximum number of attempts.
+	if hits := primary.hits.Load(); hits != defEndpointFailures {
+		t.Errorf("primary hits = %d, want %d", hits, defEndpointFailures)
+	}
+	if c.Endpoints[0].Healthy() || !c.Endpoints[1].Healthy() {
+		t.Errorf("Endpoint.Healthy() = %t, %t, want false, true", c.Endpoints[0].Healthy(), c.Endpoints[1].Healthy())
+	}
+	if c.BaseURL != primary.URL+"/api" {
+		t.Errorf("Client.BaseURL = %s, want the first endpoint", c.BaseURL)
+	}
+}
+
+type testSigningAuth struct{}
+
+func (testSigningAuth) Authorize(r *http.Request) error {
+	r.Header.Set("X-Signed-Host", r.URL.Host)
+	return nil
+}
+
+func TestClient_DoEndpointsFailoverAuthorize(t *testing.T) {
+	primary := newTestEndpointServer("primary", http.StatusServiceUnavailable)
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-Signed-Host") == r.Host)
+	}))
+	defer secondary.Close()
+
+	c := newEndpointClient(nil, primary.URL, secondary.URL)
+	c.Authorizer = testSigningAuth{}
+	got, err := c.Do(context.Background(), &testItemsRequest{path: "/items"})
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	if string(got.Data) != "true" {
+		t.Errorf("Client.Do() failover request was not signed for the secondary endpoint")
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	a := newTestEndpointServer("a", http.StatusOK)
+	defer a.Close()
+	b := newTestEndpointServer("b", http.StatusOK)
+	defer b.Close()
+
+	c := newEndpointClient(RoundRobin(), a.URL, b.URL)
+	for i := 0; i < 6; i++ {
+		if _, err := c.Do(context.Background(), &testGetRequest{}); err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+	}
+	if a.hits.Load() != 3 || b.hits.Load() != 3 {
+		t.Errorf("RoundRobin() hits = %d, %d, want 3, 3", a.hits.Load(), b.hits.Load())
+	}
+}
+
+func TestEndpointSelectors(t *testing.T) {
+	newEndpoints := func() []*Endpoint {
+		fast, slow, unmeasured := &Endpoint{URL: "fast"}, &Endpoint{URL: "slow"}, &Endpoint{URL: "unmeasured"}
+		fast.record(&http.Response{StatusCode: 200}, nil, time.Millisecond)
+		slow.record(&http.Response{StatusCode: 200}, nil, time.Second)
+		return []*Endpoint{slow, fast, unmeasured}
+	}
+	unhealthy := func(e *Endpoint) *Endpoint {
+		for i := 0; i < defEndpointFailures; i++ {
+			e.record(nil, fmt.Errorf("connection refused"), 0)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		selector EndpointSelector
+		tried    func([]*Endpoint) []*Endpoint
+		prepare  func([]*Endpoint)
+		want     string
+	}{
+		{name: "primary", selector: PrimaryFailover(), want: "slow"},
+		{
+			name:     "primary unhealthy",
+			selector: PrimaryFailover(),
+			prepare:  func(e []*Endpoint) { unhealthy(e[0]) },
+			want:     "fast",
+		},
+		{
+			name:     "failover on retry",
+			selector: PrimaryFailover(),
+			tried:    func(e []*Endpoint) []*Endpoint { return e[:2] },
+			want:     "unmeasured",
+		},
+		{
+			name:     "all tried",
+			selector: PrimaryFailover(),
+			tried:    func(e []*Endpoint) []*Endpoint { return e },
+			want:     "slow",
+		},
+		{name: "least latency unmeasured first", selector: LeastLatency(), want: "unmeasured"},
+		{
+			name:     "least latency",
+			selector: LeastLatency(),
+			tried:    func(e []*Endpoint) []*Endpoint { return e[2:] },
+			want:     "fast",
+		},
+		{
+			name:     "weighted skips zero chance endpoints",
+			selector: Weighted(),
+			prepare:  func(e []*Endpoint) { unhealthy(e[0]); unhealthy(e[2]) },
+			want:     "fast",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints := newEndpoints()
+			if tt.prepare != nil {
+				tt.prepare(endpoints)
+			}
+			var tried []*Endpoint
+			if tt.tried != nil {
+				tried = tt.tried(endpoints)
+			}
+			if got := tt.selector(endpoints, tried); got.URL != tt.want {
+				t.Errorf("EndpointSelector() = %s, want %s", got.URL, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	heavy, light := &Endpoint{URL: "heavy", Weight: 9}, &Endpoint{URL: "light"}
+	endpoints := []*Endpoint{heavy, light}
+	selector := Weighted()
+
+	var heavyCount int
+	for i := 0; i < 1000; i++ {
+		if selector(endpoints, nil) == heavy {
+			heavyCount++
+		}
+	}
+	if heavyCount < 800 || heavyCount > 980 {
+		t.Errorf("Weighted() selected the heavy endpoint %d times out of 1000", heavyCount)
+	}
+}
@@ -0,0 +1,136 @@
+// Package jsonpath implements a minimal subset of JSONPath for selecting
+// values from JSON documents, such as "$.data.items" or "results[0].id".
+//
+// A path consists of dot separated object keys and bracketed array indices.
+// The leading "$" is optional and numeric keys also index arrays.
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a single step of a path.
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parse splits the path into its segments.
+func parse(path string) ([]segment, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segs []segment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			continue
+		}
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			segs = append(segs, segment{key: key})
+		}
+		for rest != "" {
+			idx, tail, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("jsonpath: unterminated index in %q", path)
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid index %q in %q", idx, path)
+			}
+			segs = append(segs, segment{index: n, isIdx: true})
+			rest = strings.TrimPrefix(tail, "[")
+		}
+	}
+
+	return segs, nil
+}
+
+// Lookup decodes the JSON document and returns the value at the path.
+// Numbers are returned as json.Number. The boolean result reports whether
+// the path exists in the document.
+func Lookup(data []byte, path string) (any, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false, err
+	}
+
+	return Get(v, path)
+}
+
+// Get returns the value at the path within an already decoded JSON value.
+func Get(v any, path string) (any, bool, error) {
+	segs, err := parse(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, seg := range segs {
+		switch x := v.(type) {
+		case map[string]any:
+			if seg.isIdx {
+				return nil, false, nil
+			}
+			next, ok := x[seg.key]
+			if !ok {
+				return nil, false, nil
+			}
+			v = next
+		case []any:
+			i := seg.index
+			if !seg.isIdx {
+				if i, err = strconv.Atoi(seg.key); err != nil {
+					return nil, false, nil
+				}
+			}
+			if i < 0 || i >= len(x) {
+				return nil, false, nil
+			}
+			v = x[i]
+		default:
+			return nil, false, nil
+		}
+	}
+
+	return v, true, nil
+}
+
+// String returns the string representation of a scalar JSON value.
+// It returns false for null, objects and arrays.
+func String(v any) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(x), true
+	default:
+		return "", false
+	}
+}
+
+// Int returns the integer value of a JSON number or numeric string.
+func Int(v any) (int, bool) {
+	s, ok := String(v)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		return int(f), true
+	}
+
+	return n, true
+}
@@ -0,0 +1,64 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	doc := []byte(`{"status":"Succeeded","data":{"items":[{"id":1},{"id":2}],"next":null},"total":"42"}`)
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "root key", path: "status", want: "Succeeded", wantOK: true},
+		{name: "dollar prefix", path: "$.status", want: "Succeeded", wantOK: true},
+		{name: "nested index", path: "$.data.items[1].id", want: json.Number("2"), wantOK: true},
+		{name: "numeric key", path: "data.items.0.id", want: json.Number("1"), wantOK: true},
+		{name: "null", path: "data.next", want: nil, wantOK: true},
+		{name: "missing key", path: "data.cursor", wantOK: false},
+		{name: "index out of range", path: "data.items[5]", wantOK: false},
+		{name: "index on object", path: "data[0]", wantOK: false},
+		{name: "root", path: "$", want: nil, wantOK: true},
+		{name: "invalid index", path: "data.items[a]", wantErr: true},
+		{name: "unterminated index", path: "data.items[0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := Lookup(doc, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Lookup() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.path != "$" && ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		v      any
+		want   int
+		wantOK bool
+	}{
+		{v: json.Number("42"), want: 42, wantOK: true},
+		{v: "17", want: 17, wantOK: true},
+		{v: 3.0, want: 3, wantOK: true},
+		{v: "abc", wantOK: false},
+		{v: nil, wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := Int(tt.v)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Int(%v) = %d, %v, want %d, %v", tt.v, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
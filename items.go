@@ -0,0 +1,95 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+
+	"github.com/darrae/jac/internal/jsonpath"
+)
+
+// Extractor returns the items of a page.
+type Extractor[T any] func(*Response) ([]T, error)
+
+// ItemsAt returns an Extractor decoding the JSON array at the path of every
+// page into items of type T. An empty path selects the whole page. Pages
+// where the array is null have no items.
+func ItemsAt[T any](path string) Extractor[T] {
+	return func(res *Response) ([]T, error) {
+		v, ok, err := jsonpath.Lookup(res.Data, path)
+		if err != nil {
+			return nil, fmt.Errorf("jac: decoding page: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("jac: no items at %q", path)
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("jac: decoding items at %q: %w", path, err)
+		}
+
+		return items, nil
+	}
+}
+
+// ItemsOption configures the iteration of Items.
+type ItemsOption func(opts *itemsOptions)
+
+type itemsOptions struct {
+	maxItems int
+}
+
+// WithMaxItems stops the iteration after n items. Pages
+// beyond the one holding the last item are not requested.
+func WithMaxItems(n int) ItemsOption {
+	return func(opts *itemsOptions) {
+		opts.maxItems = n
+	}
+}
+
+// Items returns an iterator over the items of the pages of the
+// PaginatedRequest, extracted from every page by extract.
+//
+// Pages are requested lazily like with Client.Pages, so breaking out of the
+// loop stops requesting further pages. A failed request or extraction
+// yields its error and ends the iteration.
+func Items[T any](ctx context.Context, c *Client, req PaginatedRequest, extract Extractor[T], opts ...ItemsOption) iter.Seq2[T, error] {
+	var o itemsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		var n int
+		for res, err := range c.Pages(ctx, req) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			items, err := extract(res)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if o.maxItems > 0 && n >= o.maxItems {
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+				n++
+			}
+			if o.maxItems > 0 && n >= o.maxItems {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID int `json:"id"`
+}
+
+func TestItems(t *testing.T) {
+	var requests int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		switch r.URL.Query().Get("page") {
+		case "0":
+			w.Write([]byte(`{"data":{"items":[{"id":1},{"id":2}]},"has_more":true}`))
+		case "1":
+			w.Write([]byte(`{"data":{"items":[{"id":3},{"id":4}]},"has_more":true}`))
+		default:
+			w.Write([]byte(`{"data":{"items":[{"id":5}]},"has_more":false}`))
+		}
+	}))
+	defer svr.Close()
+
+	errExtract := errors.New("extract")
+	tests := []struct {
+		name         string
+		extract      Extractor[testItem]
+		opts         []ItemsOption
+		stopAfter    int
+		want         []int
+		wantRequests int64
+		wantErr      error
+	}{
+		{name: "all items", extract: ItemsAt[testItem]("data.items"), want: []int{1, 2, 3, 4, 5}, wantRequests: 3},
+		{name: "max items", extract: ItemsAt[testItem]("$.data.items"), opts: []ItemsOption{WithMaxItems(2)}, want: []int{1, 2}, wantRequests: 1},
+		{name: "break", extract: ItemsAt[testItem]("data.items"), stopAfter: 3, want: []int{1, 2, 3}, wantRequests: 2},
+		{
+			name: "extract func",
+			extract: func(res *Response) ([]testItem, error) {
+				return []testItem{{ID: 42}, {ID: 43}}, nil
+			},
+			opts:         []ItemsOption{WithMaxItems(1)},
+			want:         []int{42},
+			wantRequests: 1,
+		},
+		{
+			name: "extract error",
+			extract: func(res *Response) ([]testItem, error) {
+				return nil, errExtract
+			},
+			wantErr:      errExtract,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&requests, 0)
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+			req := &PagePaginator{Request: &testItemsRequest{path: "/"}}
+
+			var got []int
+			var err error
+			for item, e := range Items(context.Background(), c, req, tt.extract, tt.opts...) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, item.ID)
+				if len(got) == tt.stopAfter {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Items() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Items() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Items() = %v, want %v", got, tt.want)
+				}
+			}
+			if n := atomic.LoadInt64(&requests); n != tt.wantRequests {
+				t.Errorf("Items() requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
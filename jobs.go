@@ -0,0 +1,315 @@
+package jac
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AsyncJob is a pending async request recorded in a JobStore.
+type AsyncJob struct {
+	ID string `json:"id"`
+
+	// Kind is the JobKind of the ResumableRequest.
+	Kind string `json:"kind"`
+
+	// State is the output of MarshalJob of the ResumableRequest.
+	State json.RawMessage `json:"state"`
+
+	// Submitted is the time the request was first polled.
+	Submitted time.Time `json:"submitted"`
+}
+
+// ResumableRequest is an AsyncRequest whose polling state can be recorded
+// in a JobStore and restored with the JobDecoder registered for its kind.
+type ResumableRequest interface {
+	AsyncRequest
+
+	// JobKind returns the kind the JobDecoder of the request is registered with.
+	JobKind() string
+
+	// MarshalJob returns the JSON encoded state required to resume polling.
+	MarshalJob() ([]byte, error)
+}
+
+// JobDecoder restores a ResumableRequest from the state returned by its MarshalJob.
+type JobDecoder func(state []byte) (AsyncRequest, error)
+
+var (
+	jobKindsMu sync.RWMutex
+	jobKinds   = map[string]JobDecoder{}
+)
+
+// RegisterJobKind registers the JobDecoder for the ResumableRequests of the kind.
+func RegisterJobKind(kind string, decode JobDecoder) {
+	jobKindsMu.Lock()
+	jobKinds[kind] = decode
+	jobKindsMu.Unlock()
+}
+
+// decodeJob restores the AsyncRequest of the job.
+func decodeJob(job *AsyncJob) (AsyncRequest, error) {
+	jobKindsMu.RLock()
+	decode, ok := jobKinds[job.Kind]
+	jobKindsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("jac: unknown job kind %q", job.Kind)
+	}
+
+	return decode(job.State)
+}
+
+// JobStore records pending async requests.
+//
+// The jobs of ResumableRequests are saved after the polls that are not
+// ready when their state changes. They are deleted once the request has
+// finished: it was ready, IsReady returned an error or the PollPolicy
+// limits were reached. Jobs of requests failing otherwise, such as on an
+// interrupted context or a transport error, are kept to be resumed.
+type JobStore interface {
+	Save(ctx context.Context, job *AsyncJob) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*AsyncJob, error)
+}
+
+// ResumeAsync resumes polling the jobs recorded in the store and sends
+// their results on the returned channel like DoAsync.
+//
+// The jobs keep being recorded in the store while they are polled.
+// Jobs of unknown kinds result in an error and are kept in the store.
+func (c *Client) ResumeAsync(ctx context.Context, store JobStore) (chan *AsyncResponse, error) {
+	c.once.Do(c.init)
+	jobs, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	asyncs := make([]AsyncRequest, len(jobs))
+	errs := make([]error, len(jobs))
+	for i, job := range jobs {
+		asyncs[i], errs[i] = decodeJob(job)
+	}
+
+	ch := make(chan *AsyncResponse)
+	go c.doAsyncs(ch, ctx, asyncs, func(ctx context.Context, i int, request AsyncRequest, p *poller) *AsyncResponse {
+		if errs[i] != nil {
+			return &AsyncResponse{Err: errs[i]}
+		}
+		p.jobs = store
+		p.job = jobs[i]
+		p.start = jobs[i].Submitted
+		return c.poll(ctx, request, p)
+	})
+
+	return ch, nil
+}
+
+// recordJob saves the polling state of a ResumableRequest when it changes.
+func (c *Client) recordJob(ctx context.Context, request AsyncRequest, p *poller) error {
+	r, ok := request.(ResumableRequest)
+	if !ok || p.jobs == nil {
+		return nil
+	}
+
+	state, err := r.MarshalJob()
+	if err != nil {
+		return err
+	}
+	if p.job == nil {
+		p.job = &AsyncJob{ID: uuid.NewString(), Kind: r.JobKind(), Submitted: p.start}
+	} else if bytes.Equal(p.job.State, state) {
+		return nil
+	}
+	p.job.State = state
+
+	if err := p.jobs.Save(ctx, p.job); err != nil {
+		return fmt.Errorf("jac: recording async job: %w", err)
+	}
+
+	return nil
+}
+
+// finishJob deletes the recorded job of a request that has finished.
+func (c *Client) finishJob(ctx context.Context, p *poller, res *AsyncResponse) {
+	if p.job == nil || p.jobs == nil || !p.finished {
+		return
+	}
+
+	_ = p.jobs.Delete(context.WithoutCancel(ctx), p.job.ID)
+}
+
+// maxJobEntrySize is the maximum size of a line of the FileJobStore log.
+const maxJobEntrySize = 10 << 20
+
+// jobEntry is a line of the FileJobStore log.
+type jobEntry struct {
+	Op  string    `json:"op"`
+	Job *AsyncJob `json:"job,omitempty"`
+	ID  string    `json:"id,omitempty"`
+}
+
+// FileJobStore is a JobStore keeping its jobs in a JSON Lines file.
+//
+// Every change is appended to the file as a save or delete entry and the
+// file is synced before returning. Compact rewrites the file with only
+// the pending jobs.
+type FileJobStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileJobStore returns a FileJobStore using the file at the path.
+// The file is created on the first change.
+func NewFileJobStore(path string) *FileJobStore {
+	return &FileJobStore{path: path}
+}
+
+// Save implements the JobStore interface.
+func (f *FileJobStore) Save(ctx context.Context, job *AsyncJob) error {
+	return f.append(&jobEntry{Op: "save", Job: job})
+}
+
+// Delete implements the JobStore interface.
+func (f *FileJobStore) Delete(ctx context.Context, id string) error {
+	return f.append(&jobEntry{Op: "delete", ID: id})
+}
+
+// List implements the JobStore interface. The jobs are
+// returned in the order they were first saved.
+func (f *FileJobStore) List(ctx context.Context) ([]*AsyncJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.list()
+}
+
+// Compact rewrites the file with the pending jobs only.
+func (f *FileJobStore) Compact() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	jobs, err := f.list()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, job := range jobs {
+		if err := enc.Encode(&jobEntry{Op: "save", Job: job}); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *FileJobStore) append(entry *jobEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+
+	// Terminate a partially written last line, so that it
+	// does not corrupt the entry appended after it.
+	line := append(data, '\n')
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// list replays the log. Malformed lines, such as a partially written
+// last line after a crash, are skipped.
+func (f *FileJobStore) list() ([]*AsyncJob, error) {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	jobs := map[string]*AsyncJob{}
+	order := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxJobEntrySize)
+	for n := 0; scanner.Scan(); n++ {
+		var entry jobEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		switch {
+		case entry.Op == "save" && entry.Job != nil:
+			if _, ok := jobs[entry.Job.ID]; !ok {
+				order[entry.Job.ID] = n
+			}
+			jobs[entry.Job.ID] = entry.Job
+		case entry.Op == "delete":
+			delete(jobs, entry.ID)
+			delete(order, entry.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]*AsyncJob, 0, len(jobs))
+	for _, job := range jobs {
+		list = append(list, job)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return order[list[i].ID] < order[list[j].ID]
+	})
+
+	return list, nil
+}
@@ -0,0 +1,239 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileJobStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store := NewFileJobStore(path)
+
+	if jobs, err := store.List(ctx); err != nil || len(jobs) != 0 {
+		t.Fatalf("FileJobStore.List() = %v, %v, want empty", jobs, err)
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := store.Save(ctx, &AsyncJob{ID: id, Kind: "test", State: json.RawMessage(`{"n":1}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = store.Save(ctx, &AsyncJob{ID: "a", Kind: "test", State: json.RawMessage(`{"n":2}`)})
+	_ = store.Delete(ctx, "b")
+
+	// A partially written line must not break the store.
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	file.WriteString(`{"op":"save","job":{"id":"d"`)
+	file.Close()
+	_ = store.Save(ctx, &AsyncJob{ID: "e", Kind: "test"})
+	_ = store.Delete(ctx, "e")
+
+	assertJobs := func(name string) {
+		jobs, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("%s: FileJobStore.List() error = %v", name, err)
+		}
+		var got []string
+		for _, job := range jobs {
+			got = append(got, job.ID+string(job.State))
+		}
+		if want := `a{"n":2},c{"n":1}`; strings.Join(got, ",") != want {
+			t.Errorf("%s: FileJobStore.List() = %v, want %s", name, got, want)
+		}
+	}
+	assertJobs("log")
+
+	if err := store.Compact(); err != nil {
+		t.Fatalf("FileJobStore.Compact() error = %v", err)
+	}
+	assertJobs("compacted")
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("FileJobStore.Compact() lines = %d, want 2", lines)
+	}
+}
+
+type testResumableRequest struct {
+	*GetRequest
+	ID string `json:"id"`
+}
+
+func (t *testResumableRequest) Path() string {
+	return "/reports/" + t.ID
+}
+
+func (t *testResumableRequest) IsReady(res *Response) (bool, error) {
+	return string(res.Data) == "ready", nil
+}
+
+func (t *testResumableRequest) OnReady() Request {
+	return &testResultRequest{id: t.ID}
+}
+
+func (t *testResumableRequest) JobKind() string {
+	return "test-report"
+}
+
+func (t *testResumableRequest) MarshalJob() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func TestClient_ResumeAsync(t *testing.T) {
+	var mu sync.Mutex
+	ready := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /reports/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ready {
+			fmt.Fprint(w, "ready")
+			return
+		}
+		fmt.Fprint(w, "pending")
+	})
+	mux.HandleFunc("GET /results/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "result "+r.PathValue("id"))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	store := NewFileJobStore(filepath.Join(t.TempDir(), "jobs.jsonl"))
+	newClient := func() *Client {
+		return &Client{
+			BaseURL:           svr.URL,
+			DisableLogging:    true,
+			DisableCoalescing: true,
+			Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			Poll:              &PollPolicy{Interval: time.Millisecond},
+			Jobs:              store,
+		}
+	}
+
+	// The worker is interrupted while the reports are pending.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	for _, res := range newClient().DoAsyncCollect(ctx, &testResumableRequest{ID: "1"}, &testResumableRequest{ID: "2"}) {
+		if res.Err == nil {
+			t.Fatalf("Client.DoAsync() error = nil, want interrupted")
+		}
+	}
+	jobs, _ := store.List(context.Background())
+	if len(jobs) != 2 {
+		t.Fatalf("JobStore jobs = %d, want 2", len(jobs))
+	}
+
+	RegisterJobKind("test-report", func(state []byte) (AsyncRequest, error) {
+		req := &testResumableRequest{}
+		return req, json.Unmarshal(state, req)
+	})
+	mu.Lock()
+	ready = true
+	mu.Unlock()
+
+	ch, err := newClient().ResumeAsync(context.Background(), store)
+	if err != nil {
+		t.Fatalf("Client.ResumeAsync() error = %v", err)
+	}
+	var got []string
+	for res := range ch {
+		if res.Err != nil {
+			t.Fatalf("Client.ResumeAsync() result error = %v", res.Err)
+		}
+		got = append(got, string(res.Response.Data))
+	}
+	if len(got) != 2 {
+		t.Errorf("Client.ResumeAsync() results = %v", got)
+	}
+	if jobs, _ := store.List(context.Background()); len(jobs) != 0 {
+		t.Errorf("JobStore jobs = %d after resume, want 0", len(jobs))
+	}
+}
+
+func TestClient_ResumeAsyncUnknownKind(t *testing.T) {
+	store := NewFileJobStore(filepath.Join(t.TempDir(), "jobs.jsonl"))
+	_ = store.Save(context.Background(), &AsyncJob{ID: "x", Kind: "unknown"})
+
+	c := &Client{BaseURL: "https://example.com", DisableLogging: true}
+	ch, err := c.ResumeAsync(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := <-ch; res.Err == nil {
+		t.Errorf("Client.ResumeAsync() error = nil, want unknown kind")
+	}
+	if jobs, _ := store.List(context.Background()); len(jobs) != 1 {
+		t.Errorf("JobStore jobs = %d, want the unknown job kept", len(jobs))
+	}
+}
+
+type countingJobStore struct {
+	JobStore
+	saves int
+}
+
+func (c *countingJobStore) Save(ctx context.Context, job *AsyncJob) error {
+	c.saves++
+	return c.JobStore.Save(ctx, job)
+}
+
+func TestClient_DoAsyncKeepsJob(t *testing.T) {
+	var polls int
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch {
+		case polls <= 3:
+			fmt.Fprint(w, "pending")
+		case r.URL.Path == "/reports/timeout":
+			fmt.Fprint(w, "pending")
+		default:
+			w.WriteHeader(503)
+		}
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		id       string
+		maxPolls int
+		wantJobs int
+	}{
+		{name: "transport outage", id: "outage", wantJobs: 1},
+		{name: "poll timeout", id: "timeout", maxPolls: 5, wantJobs: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls = 0
+			store := &countingJobStore{JobStore: NewFileJobStore(filepath.Join(t.TempDir(), "jobs.jsonl"))}
+			c := &Client{
+				BaseURL:           svr.URL,
+				DisableLogging:    true,
+				DisableCoalescing: true,
+				Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+				Poll:              &PollPolicy{Interval: time.Millisecond, MaxPolls: tt.maxPolls},
+				Jobs:              store,
+			}
+			for _, res := range c.DoAsyncCollect(context.Background(), &testResumableRequest{ID: tt.id}) {
+				if res.Err == nil {
+					t.Fatalf("Client.DoAsync() error = nil")
+				}
+			}
+
+			if store.saves != 1 {
+				t.Errorf("JobStore saves = %d, want 1 for an unchanged state", store.saves)
+			}
+			if jobs, _ := store.List(context.Background()); len(jobs) != tt.wantJobs {
+				t.Errorf("JobStore jobs = %d, want %d", len(jobs), tt.wantJobs)
+			}
+		})
+	}
+}
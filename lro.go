@@ -0,0 +1,273 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrae/jac/internal/jsonpath"
+)
+
+// operationHeaders are the response headers pointing to the status of a
+// long-running operation, in the order of precedence.
+var operationHeaders = []string{"Azure-AsyncOperation", "Operation-Location", "Location"}
+
+// defLROStatusPath is the default JSON path of the operation status.
+const defLROStatusPath = "status"
+
+var (
+	defLROSucceeded = []string{"succeeded", "success", "completed", "complete", "done"}
+	defLROFailed    = []string{"failed", "failure", "canceled", "cancelled", "error"}
+)
+
+// LROOptions configures how DoLRO determines the state of an operation.
+type LROOptions struct {
+	// StatusPath is the JSON path of the operation status in the poll
+	// responses. Default is "status".
+	StatusPath string
+
+	// SucceededStates are the status values of a successfully completed
+	// operation. The comparison is case insensitive.
+	// Default is succeeded, success, completed, complete and done.
+	SucceededStates []string
+
+	// FailedStates are the status values of a failed operation.
+	// The comparison is case insensitive.
+	// Default is failed, failure, canceled, cancelled and error.
+	FailedStates []string
+
+	// ResultPath is the optional JSON path of the result URL
+	// in the poll response of a completed operation.
+	ResultPath string
+}
+
+// LROError is returned by DoLRO when the operation ends in a failed state.
+type LROError struct {
+	Status   string
+	Response *Response
+}
+
+// Error implements the error interface.
+func (e *LROError) Error() string {
+	return "jac: long-running operation ended with status " + e.Status
+}
+
+// DoLRO makes the request and waits for the long-running operation it starts
+// to complete.
+//
+// When the Response includes an Azure-AsyncOperation, Operation-Location or
+// Location header, the URL in the header is polled until the status found at
+// the StatusPath of the poll response is terminal. Poll responses without a
+// status are considered complete unless their status code is 202 Accepted.
+// The waits between polls follow the Client's PollPolicy, but the duration of
+// a Retry-After header takes precedence.
+//
+// Once the operation succeeds, the result is fetched from the URL found at the
+// ResultPath, the Location header of the last poll response or the Location
+// header of the initial response, in that order. PUT and PATCH operations
+// without such a URL fetch the result from the URL of the request.
+// Otherwise the last poll response is returned.
+//
+// The Location header of an initial response is only followed when its status
+// code is 202 Accepted. Responses without any of the headers are returned as is.
+//
+// The status and result URLs are only authorized when they have the origin of
+// the BaseURL or of one of the Endpoints of the Client.
+func (c *Client) DoLRO(ctx context.Context, req Request, opts *LROOptions) (*Response, error) {
+	if opts == nil {
+		opts = &LROOptions{}
+	}
+
+	submit, err := c.Do(ctx, req)
+	if err != nil {
+		return submit, err
+	}
+	header, statusURL := operationURL(submit, submit.URL)
+	if header == "Location" && submit.StatusCode != http.StatusAccepted {
+		// A Location header only points to an operation on 202 Accepted,
+		// 201 Created uses it for the created resource.
+		statusURL = nil
+	}
+	if statusURL == nil {
+		return submit, nil
+	}
+
+	p := newPoller(c.Poll)
+	hint := retryAfter(submit.Header)
+	for {
+		if err := p.next(ctx, hint); err != nil {
+			return nil, err
+		}
+
+		res, err := c.getURL(ctx, statusURL.String())
+		p.polls++
+		if err != nil {
+			return res, err
+		}
+
+		status, ok := opts.status(res)
+		switch {
+		case !ok && res.StatusCode != http.StatusAccepted:
+			return res, nil
+		case ok && opts.hasFailed(status):
+			return res, &LROError{Status: status, Response: res}
+		case ok && opts.hasSucceeded(status):
+			resultURL := opts.resultURL(res, submit, header, req.Method())
+			if resultURL == "" {
+				return res, nil
+			}
+			return c.getURL(ctx, resultURL)
+		}
+
+		if h, u := operationURL(res, statusURL); u != nil && h == header {
+			statusURL = u
+		}
+		hint = retryAfter(res.Header)
+	}
+}
+
+// getURL makes a GET request to the absolute URL. The request is only
+// authorized and given the default headers when the URL has the origin of
+// the BaseURL or of one of the Endpoints, so that the credentials of the
+// Client are never sent to the other hosts named by a server.
+func (c *Client) getURL(ctx context.Context, u string) (*Response, error) {
+	c.once.Do(c.init)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, bytes.NewReader(nil))
+	if err != nil {
+		return nil, err
+	}
+	if c.isOwnURL(req.URL) {
+		if err := c.prepRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.do(req)
+}
+
+// isOwnURL reports whether the URL has the origin
+// of the BaseURL or of one of the Endpoints.
+func (c *Client) isOwnURL(u *url.URL) bool {
+	bases := []string{c.BaseURL}
+	for _, e := range c.Endpoints {
+		bases = append(bases, e.URL)
+	}
+	for _, base := range bases {
+		if b, err := url.Parse(base); err == nil && sameOrigin(b, u) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// status returns the operation status of the poll response.
+func (o *LROOptions) status(res *Response) (string, bool) {
+	path := o.StatusPath
+	if path == "" {
+		path = defLROStatusPath
+	}
+	v, ok, err := jsonpath.Lookup(res.Data, path)
+	if err != nil || !ok {
+		return "", false
+	}
+
+	return jsonpath.String(v)
+}
+
+func (o *LROOptions) hasSucceeded(status string) bool {
+	return containsFold(o.SucceededStates, defLROSucceeded, status)
+}
+
+func (o *LROOptions) hasFailed(status string) bool {
+	return containsFold(o.FailedStates, defLROFailed, status)
+}
+
+// resultURL returns the URL of the result of a completed operation
+// or an empty string if the last poll response is the result.
+func (o *LROOptions) resultURL(last, submit *Response, header, method string) string {
+	if o.ResultPath != "" {
+		if v, ok, err := jsonpath.Lookup(last.Data, o.ResultPath); err == nil && ok {
+			if u, ok := jsonpath.String(v); ok && u != "" {
+				return resolveURL(last.URL, u)
+			}
+		}
+	}
+	if loc := last.Header.Get("Location"); loc != "" {
+		return resolveURL(last.URL, loc)
+	}
+	if loc := submit.Header.Get("Location"); loc != "" && header != "Location" {
+		return resolveURL(submit.URL, loc)
+	}
+	if (method == "PUT" || method == "PATCH") && submit.URL != nil {
+		return submit.URL.String()
+	}
+
+	return ""
+}
+
+// operationURL returns the first operation header of the Response
+// and its URL resolved against the base URL.
+func operationURL(res *Response, base *url.URL) (string, *url.URL) {
+	for _, header := range operationHeaders {
+		loc := res.Header.Get(header)
+		if loc == "" {
+			continue
+		}
+		u, err := url.Parse(resolveURL(base, loc))
+		if err != nil {
+			continue
+		}
+		return header, u
+	}
+
+	return "", nil
+}
+
+// resolveURL resolves the possibly relative reference against the base URL.
+func resolveURL(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || base == nil {
+		return ref
+	}
+
+	return base.ResolveReference(u).String()
+}
+
+// retryAfter returns the duration of the Retry-After header given
+// either in seconds or as an HTTP date. It returns 0 if there is none.
+func retryAfter(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// containsFold reports whether the value is among the values,
+// or among the defaults when values is empty, ignoring case.
+func containsFold(values, defaults []string, value string) bool {
+	if len(values) == 0 {
+		values = defaults
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,241 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLRORequest struct {
+	*PostRequest
+	path string
+}
+
+func (t *testLRORequest) Path() string {
+	return t.path
+}
+
+func (t *testLRORequest) Body() []byte {
+	return []byte(`{}`)
+}
+
+func newLROServer() *httptest.Server {
+	var mu sync.Mutex
+	polls := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /azure", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Azure-AsyncOperation", "/ops/azure")
+		w.Header().Set("Location", "/results/azure")
+		w.WriteHeader(202)
+	})
+	mux.HandleFunc("POST /location", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/poll/location")
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(202)
+	})
+	mux.HandleFunc("POST /failing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Operation-Location", "/ops/failing")
+		w.WriteHeader(202)
+	})
+	mux.HandleFunc("POST /custom", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Operation-Location", "/ops/custom")
+		w.WriteHeader(202)
+	})
+	mux.HandleFunc("POST /created", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/items/1")
+		w.WriteHeader(201)
+		fmt.Fprint(w, `{"status":"active"}`)
+	})
+	mux.HandleFunc("POST /sync", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "sync result")
+	})
+	mux.HandleFunc("GET /ops/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls[r.URL.Path]++
+		n := polls[r.URL.Path]
+		mu.Unlock()
+		id := r.PathValue("id")
+		switch {
+		case n < 3:
+			fmt.Fprint(w, `{"status":"Running","properties":{"state":"Updating"}}`)
+		case id == "failing":
+			fmt.Fprint(w, `{"status":"Failed"}`)
+		case id == "custom":
+			fmt.Fprint(w, `{"properties":{"state":"Ready","output":"/results/custom"}}`)
+		default:
+			fmt.Fprint(w, `{"status":"Succeeded"}`)
+		}
+	})
+	mux.HandleFunc("GET /poll/location", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls[r.URL.Path]++
+		n := polls[r.URL.Path]
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(202)
+			return
+		}
+		fmt.Fprint(w, "location result")
+	})
+	mux.HandleFunc("GET /results/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.PathValue("id")+" result")
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestClient_DoLRO(t *testing.T) {
+	svr := newLROServer()
+	defer svr.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		opts       *LROOptions
+		want       string
+		wantStatus string
+	}{
+		{name: "azure async operation", path: "/azure", want: "azure result"},
+		{name: "location", path: "/location", want: "location result"},
+		{name: "synchronous", path: "/sync", want: "sync result"},
+		{name: "created", path: "/created", want: `{"status":"active"}`},
+		{name: "failed", path: "/failing", wantStatus: "Failed"},
+		{
+			name: "custom paths",
+			path: "/custom",
+			opts: &LROOptions{
+				StatusPath:      "$.properties.state",
+				SucceededStates: []string{"ready"},
+				ResultPath:      "properties.output",
+			},
+			want: "custom result",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+				Poll:           &PollPolicy{Interval: time.Millisecond, MaxPolls: 10},
+			}
+			got, err := c.DoLRO(context.Background(), &testLRORequest{path: tt.path}, tt.opts)
+
+			var lroErr *LROError
+			if tt.wantStatus != "" {
+				if !errors.As(err, &lroErr) || lroErr.Status != tt.wantStatus {
+					t.Errorf("Client.DoLRO() error = %v, want status %s", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Client.DoLRO() error = %v", err)
+			}
+			if string(got.Data) != tt.want {
+				t.Errorf("Client.DoLRO() = %s, want %s", got.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "missing"},
+		{name: "seconds", value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "invalid", value: "soon"},
+		{
+			name:  "date",
+			value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+			min:   58 * time.Second,
+			max:   time.Minute,
+		},
+		{name: "past date", value: "Mon, 02 Jan 2006 15:04:05 GMT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.value != "" {
+				h.Set("Retry-After", tt.value)
+			}
+			if got := retryAfter(h); got < tt.min || got > tt.max {
+				t.Errorf("retryAfter() = %s, want between %s and %s", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+type testBearerAuth struct{}
+
+func (testBearerAuth) Authorize(r *http.Request) error {
+	r.Header.Set("Authorization", "Bearer secret")
+	return nil
+}
+
+func TestClient_DoLROCrossHost(t *testing.T) {
+	var mu sync.Mutex
+	auths := map[string]string{}
+	record := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auths[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+	}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(w, r)
+		fmt.Fprint(w, `{"status":"Succeeded"}`)
+	}))
+	defer other.Close()
+	own := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(w, r)
+		switch r.URL.Path {
+		case "/op":
+			fmt.Fprint(w, `{"status":"Succeeded"}`)
+			return
+		case "/other":
+			w.Header().Set("Operation-Location", other.URL+"/op")
+		default:
+			w.Header().Set("Operation-Location", "/op")
+		}
+		w.WriteHeader(202)
+	}))
+	defer own.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "same origin", path: "/own", want: "Bearer secret"},
+		{name: "other origin", path: "/other", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				BaseURL:        own.URL,
+				Authorizer:     testBearerAuth{},
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+				Poll:           &PollPolicy{Interval: time.Millisecond, MaxPolls: 3},
+			}
+			if _, err := c.DoLRO(context.Background(), &testLRORequest{path: tt.path}, nil); err != nil {
+				t.Fatalf("Client.DoLRO() error = %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if got := auths["/op"]; got != tt.want {
+				t.Errorf("Client.DoLRO() poll Authorization = %q, want %q", got, tt.want)
+			}
+			delete(auths, "/op")
+		})
+	}
+}
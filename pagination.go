@@ -2,6 +2,7 @@ package jac
 
 import (
 	"context"
+	"iter"
 )
 
 // PaginatedRequest is the interface implemented by Requests that support pagination.
@@ -20,16 +21,103 @@ type PaginatedRequest interface {
 	Next(*Response) (PaginatedRequest, bool)
 }
 
+// Pages returns an iterator over the pages of the PaginatedRequest.
+//
+// Pages are requested lazily, one at a time, as the iteration advances.
+// Breaking out of the loop stops requesting further pages. A failed request
+// yields its error and ends the iteration.
+func (c *Client) Pages(ctx context.Context, p PaginatedRequest) iter.Seq2[*Response, error] {
+	return func(yield func(*Response, error) bool) {
+		req := p
+		for done := false; !done; {
+			res, err := c.Do(ctx, req)
+			if err != nil {
+				yield(res, err)
+				return
+			}
+			if !yield(res, nil) {
+				return
+			}
+			req, done = req.Next(res)
+		}
+	}
+}
+
+// DoPagination requests all the pages of the PaginatedRequest.
+// Use Pages to process the pages as they are fetched.
+//
+// When a request fails, the pages fetched before it are
+// returned along with the error.
 func (c *Client) DoPagination(ctx context.Context, p PaginatedRequest) ([]*Response, error) {
 	var responses []*Response
-	var done bool
-	for !done {
-		res, err := c.Do(ctx, p)
+	for res, err := range c.Pages(ctx, p) {
 		if err != nil {
-			return nil, err
+			return responses, err
 		}
 		responses = append(responses, res)
-		p, done = p.Next(res)
+	}
+
+	return responses, nil
+}
+
+// IndexedRequest is a PaginatedRequest whose pages can be requested
+// in any order once the first page reveals how many there are.
+type IndexedRequest interface {
+	PaginatedRequest
+
+	// PageCount returns the total number of pages, including the first
+	// one, reported by the first page. It returns false if the count is
+	// unknown.
+	PageCount(first *Response) (int, bool)
+
+	// PageAt returns the Request of the page with the zero based index n,
+	// relative to the first page.
+	PageAt(n int) Request
+}
+
+// DoPaginationParallel is like DoPagination but requests the pages after
+// the first one concurrently, at most concurrency at a time, once the first
+// page reveals the page count. The requests go through the Limiter of the
+// Client like any other and the pages are returned in order.
+//
+// When the page count is unknown the pages are requested one after another.
+// The first failed request cancels the ones in progress and the pages
+// preceding the first missing one are returned along with the error.
+func (c *Client) DoPaginationParallel(ctx context.Context, p IndexedRequest, concurrency int) ([]*Response, error) {
+	first, err := c.Do(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	count, ok := p.PageCount(first)
+	if !ok {
+		next, done := p.Next(first)
+		if done {
+			return []*Response{first}, nil
+		}
+		rest, err := c.DoPagination(ctx, next)
+		return append([]*Response{first}, rest...), err
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	responses := make([]*Response, count)
+	responses[0] = first
+	err = forEach(ctx, count-1, concurrency, true, func(ctx context.Context, i int) error {
+		res, err := c.Do(ctx, p.PageAt(i+1))
+		if err != nil {
+			return err
+		}
+		responses[i+1] = res
+		return nil
+	})
+	if err != nil {
+		n := 1
+		for n < count && responses[n] != nil {
+			n++
+		}
+		return responses[:n], err
 	}
 
 	return responses, nil
@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type testPaginatedRequest struct {
@@ -115,3 +117,126 @@ func TestClient_DoPagination(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Pages(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopAfter int
+		want      int
+	}{
+		{name: "all pages", stopAfter: -1, want: 5},
+		{name: "break", stopAfter: 2, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			handler := &paginationHandler{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				handler.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+			c := &Client{
+				BaseURL:        srv.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+
+			var got int
+			for _, err := range c.Pages(context.Background(), &testPaginatedRequest{}) {
+				if err != nil {
+					t.Fatalf("Client.Pages() error = %v", err)
+				}
+				got++
+				if got == tt.stopAfter {
+					break
+				}
+			}
+			if got != tt.want || requests != tt.want {
+				t.Errorf("Client.Pages() pages = %d, requests = %d, want %d", got, requests, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DoPaginationParallel(t *testing.T) {
+	var inFlight, maxInFlight, requests int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			max := atomic.LoadInt64(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		q := r.URL.Query()
+		item := q.Get("offset")
+		if item == "" {
+			item = q.Get("page")
+		}
+		fmt.Fprintf(w, `{"item":%s,"total":10,"last_page":9,"has_more":%t}`, item, item != "9")
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name         string
+		p            IndexedRequest
+		concurrency  int
+		want         []string
+		wantParallel bool
+	}{
+		{
+			name:         "offset",
+			p:            &OffsetPaginator{Request: &testItemsRequest{path: "/"}, TotalPath: "total", Offset: 2, Limit: 2},
+			concurrency:  2,
+			want:         []string{"2", "4", "6", "8"},
+			wantParallel: true,
+		},
+		{
+			name:         "page number",
+			p:            &PagePaginator{Request: &testItemsRequest{path: "/"}, LastPagePath: "last_page", Page: 1},
+			concurrency:  3,
+			want:         []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"},
+			wantParallel: true,
+		},
+		{
+			name:        "unknown page count",
+			p:           &PagePaginator{Request: &testItemsRequest{path: "/"}, Page: 7},
+			concurrency: 3,
+			want:        []string{"7", "8", "9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&maxInFlight, 0)
+			atomic.StoreInt64(&requests, 0)
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+			got, err := c.DoPaginationParallel(context.Background(), tt.p, tt.concurrency)
+			if err != nil {
+				t.Fatalf("Client.DoPaginationParallel() error = %v", err)
+			}
+			if len(got) != len(tt.want) || atomic.LoadInt64(&requests) != int64(len(tt.want)) {
+				t.Fatalf("Client.DoPaginationParallel() pages = %d, requests = %d, want %d", len(got), requests, len(tt.want))
+			}
+			for i, res := range got {
+				var page struct{ Item json.Number }
+				_ = json.Unmarshal(res.Data, &page)
+				if page.Item.String() != tt.want[i] {
+					t.Errorf("Client.DoPaginationParallel() page %d = %s, want item %s", i, res.Data, tt.want[i])
+				}
+			}
+			max := atomic.LoadInt64(&maxInFlight)
+			if max > int64(tt.concurrency) || (max > 1) != tt.wantParallel {
+				t.Errorf("Client.DoPaginationParallel() max in flight = %d, concurrency %d", max, tt.concurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package jac
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/darrae/jac/internal/jsonpath"
+)
+
+const (
+	defOffsetParam = "offset"
+	defLimitParam  = "limit"
+	defPageParam   = "page"
+	defHasMorePath = "has_more"
+	defCursorParam = "cursor"
+)
+
+// LinkPaginator paginates a Request by following the URL of the RFC 8288
+// Link header with rel="next" of every page.
+//
+// The next URL must share the scheme, host and base path of the Client's
+// BaseURL. Pagination ends when a page has no next link or its next link
+// points elsewhere.
+type LinkPaginator struct {
+	Request
+
+	// followed reports whether a next link was followed,
+	// in which case path and query are those of the link.
+	followed bool
+	path     string
+	query    url.Values
+}
+
+// Path returns the path of the next link or the path of the Request.
+func (l *LinkPaginator) Path() string {
+	if !l.followed {
+		return l.Request.Path()
+	}
+
+	return l.path
+}
+
+// Query returns the query of the next link or the query of the Request.
+func (l *LinkPaginator) Query() url.Values {
+	if !l.followed {
+		return l.Request.Query()
+	}
+
+	return maps.Clone(l.query)
+}
+
+// Next implements the PaginatedRequest interface.
+func (l *LinkPaginator) Next(res *Response) (PaginatedRequest, bool) {
+	link := linkNext(res.Header)
+	if link == "" || res.URL == nil {
+		return nil, true
+	}
+	next, err := res.URL.Parse(link)
+	if err != nil || next.Scheme != res.URL.Scheme || next.Host != res.URL.Host {
+		return nil, true
+	}
+
+	// The base path of the Client is what precedes the path
+	// of the current page in the URL of the Response.
+	current := strings.TrimSuffix(BuildURI(l.Path(), nil), "/")
+	base, ok := strings.CutSuffix(strings.TrimSuffix(res.URL.EscapedPath(), "/"), current)
+	if !ok || !strings.HasPrefix(next.EscapedPath(), base) {
+		return nil, true
+	}
+
+	n := *l
+	n.followed = true
+	n.path = strings.TrimPrefix(next.EscapedPath(), base)
+	n.query = next.Query()
+
+	return &n, false
+}
+
+// linkNext returns the target of the Link header with rel="next".
+func linkNext(h http.Header) string {
+	for _, header := range h.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(link, ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(param, "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// CursorPaginator paginates a Request by sending the cursor found
+// in every page as a query parameter of the next request.
+//
+// Pagination ends when the cursor is missing, null or empty.
+type CursorPaginator struct {
+	Request
+
+	// CursorPath is the JSON path of the next cursor in the response.
+	CursorPath string
+
+	// Param is the query parameter the cursor is sent in.
+	// Default is "cursor".
+	Param string
+
+	// Cursor is the cursor of the requested page.
+	// It is omitted from the first request when empty.
+	Cursor string
+}
+
+// Query returns the query of the Request with the cursor.
+func (c *CursorPaginator) Query() url.Values {
+	q := cloneQuery(c.Request.Query())
+	if c.Cursor != "" {
+		q.Set(paramOrDefault(c.Param, defCursorParam), c.Cursor)
+	}
+
+	return q
+}
+
+// Next implements the PaginatedRequest interface.
+func (c *CursorPaginator) Next(res *Response) (PaginatedRequest, bool) {
+	v, ok, err := jsonpath.Lookup(res.Data, c.CursorPath)
+	if err != nil || !ok {
+		return nil, true
+	}
+	cursor, ok := jsonpath.String(v)
+	if !ok || cursor == "" {
+		return nil, true
+	}
+
+	n := *c
+	n.Cursor = cursor
+
+	return &n, false
+}
+
+// OffsetPaginator paginates a Request by offset and limit query
+// parameters until the offset reaches the total count of items.
+type OffsetPaginator struct {
+	Request
+
+	// TotalPath is the JSON path of the total count of items in the response.
+	// Pagination ends when the total count is missing.
+	TotalPath string
+
+	// OffsetParam and LimitParam are the query parameters of the offset
+	// and the limit. Defaults are "offset" and "limit".
+	OffsetParam string
+	LimitParam  string
+
+	// Offset is the offset of the requested page.
+	Offset int
+
+	// Limit is the number of items per page.
+	Limit int
+}
+
+// Query returns the query of the Request with the offset and the limit.
+func (o *OffsetPaginator) Query() url.Values {
+	q := cloneQuery(o.Request.Query())
+	q.Set(paramOrDefault(o.OffsetParam, defOffsetParam), strconv.Itoa(o.Offset))
+	if o.Limit > 0 {
+		q.Set(paramOrDefault(o.LimitParam, defLimitParam), strconv.Itoa(o.Limit))
+	}
+
+	return q
+}
+
+// Next implements the PaginatedRequest interface.
+func (o *OffsetPaginator) Next(res *Response) (PaginatedRequest, bool) {
+	v, ok, err := jsonpath.Lookup(res.Data, o.TotalPath)
+	if err != nil || !ok || o.Limit <= 0 {
+		return nil, true
+	}
+	total, ok := jsonpath.Int(v)
+	if !ok || o.Offset+o.Limit >= total {
+		return nil, true
+	}
+
+	n := *o
+	n.Offset += o.Limit
+
+	return &n, false
+}
+
+// PageCount implements the IndexedRequest interface. The count covers the
+// items from the Offset of the first page to the total count.
+func (o *OffsetPaginator) PageCount(first *Response) (int, bool) {
+	v, ok, err := jsonpath.Lookup(first.Data, o.TotalPath)
+	if err != nil || !ok || o.Limit <= 0 {
+		return 0, false
+	}
+	total, ok := jsonpath.Int(v)
+	if !ok {
+		return 0, false
+	}
+
+	return (total - o.Offset + o.Limit - 1) / o.Limit, true
+}
+
+// PageAt implements the IndexedRequest interface.
+func (o *OffsetPaginator) PageAt(n int) Request {
+	p := *o
+	p.Offset += n * o.Limit
+
+	return &p
+}
+
+// PagePaginator paginates a Request by a page number query
+// parameter while the response reports more pages.
+type PagePaginator struct {
+	Request
+
+	// HasMorePath is the JSON path of the boolean reporting whether
+	// there are more pages. Default is "has_more".
+	HasMorePath string
+
+	// Param is the query parameter of the page number. Default is "page".
+	Param string
+
+	// LastPagePath is the optional JSON path of the number of the last page,
+	// which is the total number of pages for APIs numbering pages from 1.
+	// It allows DoPaginationParallel to request the pages concurrently.
+	LastPagePath string
+
+	// Page is the number of the requested page.
+	Page int
+}
+
+// Query returns the query of the Request with the page number.
+func (p *PagePaginator) Query() url.Values {
+	q := cloneQuery(p.Request.Query())
+	q.Set(paramOrDefault(p.Param, defPageParam), strconv.Itoa(p.Page))
+
+	return q
+}
+
+// Next implements the PaginatedRequest interface.
+func (p *PagePaginator) Next(res *Response) (PaginatedRequest, bool) {
+	v, ok, err := jsonpath.Lookup(res.Data, paramOrDefault(p.HasMorePath, defHasMorePath))
+	if err != nil || !ok {
+		return nil, true
+	}
+	if more, _ := jsonpath.String(v); more != "true" {
+		return nil, true
+	}
+
+	n := *p
+	n.Page++
+
+	return &n, false
+}
+
+// PageCount implements the IndexedRequest interface. The count covers the
+// pages from the Page of the first page to the last page.
+func (p *PagePaginator) PageCount(first *Response) (int, bool) {
+	if p.LastPagePath == "" {
+		return 0, false
+	}
+	v, ok, err := jsonpath.Lookup(first.Data, p.LastPagePath)
+	if err != nil || !ok {
+		return 0, false
+	}
+	last, ok := jsonpath.Int(v)
+	if !ok {
+		return 0, false
+	}
+
+	return last - p.Page + 1, true
+}
+
+// PageAt implements the IndexedRequest interface.
+func (p *PagePaginator) PageAt(n int) Request {
+	r := *p
+	r.Page += n
+
+	return &r
+}
+
+// cloneQuery returns a copy of the query that can be modified
+// without affecting the query of the Request.
+func cloneQuery(q url.Values) url.Values {
+	if q == nil {
+		return url.Values{}
+	}
+
+	return maps.Clone(q)
+}
+
+func paramOrDefault(param, def string) string {
+	if param == "" {
+		return def
+	}
+
+	return param
+}
+
+type linkCheckpoint struct {
+	Path  string     `json:"path"`
+	Query url.Values `json:"query"`
+}
+
+// MarshalCheckpoint implements the Checkpointer interface.
+func (l *LinkPaginator) MarshalCheckpoint() ([]byte, error) {
+	if !l.followed {
+		return json.Marshal(nil)
+	}
+
+	return json.Marshal(&linkCheckpoint{Path: l.path, Query: l.query})
+}
+
+// UnmarshalCheckpoint implements the Checkpointer interface.
+func (l *LinkPaginator) UnmarshalCheckpoint(state []byte) (Checkpointer, error) {
+	var cp *linkCheckpoint
+	if err := json.Unmarshal(state, &cp); err != nil {
+		return nil, err
+	}
+
+	n := *l
+	n.followed = cp != nil
+	if cp != nil {
+		n.path, n.query = cp.Path, cp.Query
+	}
+
+	return &n, nil
+}
+
+// MarshalCheckpoint implements the Checkpointer interface.
+func (c *CursorPaginator) MarshalCheckpoint() ([]byte, error) {
+	return json.Marshal(c.Cursor)
+}
+
+// UnmarshalCheckpoint implements the Checkpointer interface.
+func (c *CursorPaginator) UnmarshalCheckpoint(state []byte) (Checkpointer, error) {
+	n := *c
+	if err := json.Unmarshal(state, &n.Cursor); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// MarshalCheckpoint implements the Checkpointer interface.
+func (o *OffsetPaginator) MarshalCheckpoint() ([]byte, error) {
+	return json.Marshal(o.Offset)
+}
+
+// UnmarshalCheckpoint implements the Checkpointer interface.
+func (o *OffsetPaginator) UnmarshalCheckpoint(state []byte) (Checkpointer, error) {
+	n := *o
+	if err := json.Unmarshal(state, &n.Offset); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// MarshalCheckpoint implements the Checkpointer interface.
+func (p *PagePaginator) MarshalCheckpoint() ([]byte, error) {
+	return json.Marshal(p.Page)
+}
+
+// UnmarshalCheckpoint implements the Checkpointer interface.
+func (p *PagePaginator) UnmarshalCheckpoint(state []byte) (Checkpointer, error) {
+	n := *p
+	if err := json.Unmarshal(state, &n.Page); err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
@@ -0,0 +1,145 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testItemsRequest struct {
+	*GetRequest
+	path string
+}
+
+func (t *testItemsRequest) Path() string {
+	return t.path
+}
+
+func (t *testItemsRequest) Query() url.Values {
+	return url.Values{"q": {"all"}}
+}
+
+// newPaginatorServer serves 5 items at every pagination style below /api.
+func newPaginatorServer() *httptest.Server {
+	const total = 5
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/link", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < total-1 {
+			w.Header().Set("Link", fmt.Sprintf(`</api/link?q=%s&page=%d>; rel="next last", </api/link?page=0>; rel=first`, r.URL.Query().Get("q"), page+1))
+		}
+		fmt.Fprintf(w, `{"item":%d}`, page)
+	})
+	mux.HandleFunc("GET /api/cursor", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		next := `null`
+		if n < total-1 {
+			next = fmt.Sprintf(`"%d"`, n+1)
+		}
+		fmt.Fprintf(w, `{"item":%d,"meta":{"next":%s}}`, n, next)
+	})
+	mux.HandleFunc("GET /api/offset", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		fmt.Fprintf(w, `{"item":%d,"total":%d}`, offset, total)
+	})
+	mux.HandleFunc("GET /api/page", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		fmt.Fprintf(w, `{"item":%d,"has_more":%t}`, page, page < total-1)
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "all" {
+			http.Error(w, "missing base query", http.StatusBadRequest)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestPaginators(t *testing.T) {
+	svr := newPaginatorServer()
+	defer svr.Close()
+
+	tests := []struct {
+		name string
+		p    PaginatedRequest
+	}{
+		{
+			name: "link",
+			p:    &LinkPaginator{Request: &testItemsRequest{path: "/link"}},
+		},
+		{
+			name: "cursor",
+			p:    &CursorPaginator{Request: &testItemsRequest{path: "/cursor"}, CursorPath: "meta.next", Param: "after"},
+		},
+		{
+			name: "offset",
+			p:    &OffsetPaginator{Request: &testItemsRequest{path: "/offset"}, TotalPath: "total", Limit: 1},
+		},
+		{
+			name: "page number",
+			p:    &PagePaginator{Request: &testItemsRequest{path: "/page"}, Param: "p"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				BaseURL:        svr.URL + "/api",
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+			got, err := c.DoPagination(context.Background(), tt.p)
+			if err != nil {
+				t.Fatalf("DoPagination() error = %v", err)
+			}
+			if len(got) != 5 {
+				t.Fatalf("DoPagination() pages = %d, want 5", len(got))
+			}
+			for i, res := range got {
+				if want := fmt.Sprintf(`"item":%d`, i); !bytes.Contains(res.Data, []byte(want)) {
+					t.Errorf("DoPagination() page %d = %s", i, res.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestPaginators_NextCopies(t *testing.T) {
+	first := &OffsetPaginator{Request: &testItemsRequest{}, TotalPath: "total", Limit: 10}
+	next, done := first.Next(&Response{Data: []byte(`{"total":25}`)})
+	if done || next.(*OffsetPaginator).Offset != 10 || first.Offset != 0 {
+		t.Errorf("OffsetPaginator.Next() = %+v, %t, first offset %d", next, done, first.Offset)
+	}
+	if _, done := next.Next(&Response{Data: []byte(`{"total":20}`)}); !done {
+		t.Errorf("OffsetPaginator.Next() past the total is not done")
+	}
+}
+
+func Test_linkNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   string
+	}{
+		{name: "none"},
+		{name: "single", header: []string{`<https://a.com/x?page=2>; rel="next"`}, want: "https://a.com/x?page=2"},
+		{name: "several", header: []string{`</p/1>; rel="prev", </p/3>; rel="next"`}, want: "/p/3"},
+		{name: "several headers", header: []string{`</p/1>; rel=prev`, `</p/3>; title="x"; rel=next`}, want: "/p/3"},
+		{name: "multiple rels", header: []string{`</p/3>; rel="last NEXT"`}, want: "/p/3"},
+		{name: "no next", header: []string{`</p/1>; rel="prev"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{"Link": tt.header}
+			if got := linkNext(h); got != tt.want {
+				t.Errorf("linkNext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
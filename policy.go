@@ -1,8 +1,14 @@
 package jac
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"strings"
+	"syscall"
 )
 
 // defRetryCodes is the default retry code values
@@ -17,6 +23,7 @@ var DefaultPolicy RetryPolicy = RetryOn(defRetryCodes)
 // transaction and return true if it deems the situation recoverable.
 // A request may not be a retried even when the RetryPolicy return
 // true, for instance, when the maximum attempt count is reached.
+// It is not called for attempts failing without a Response; see RetryCheck.
 type RetryPolicy func(*http.Response) bool
 
 // RetryOn is a RetryPolicy where the request is only retried if the status
@@ -58,3 +65,155 @@ func RetryIdempotentsOn(codes []int) RetryPolicy {
 		return false
 	}
 }
+
+// RetryCheck specifies the failed attempts that can be retried. Unlike a
+// RetryPolicy, it is also called when no Response was received, with the
+// transport error returned by the HTTP client, and it receives the request.
+// Either res or err is nil.
+type RetryCheck func(req *http.Request, res *http.Response, err error) bool
+
+// RetryTransient is a RetryCheck retrying every transport error except
+// the ones that cannot be resolved by trying again: the cancellation of the
+// request context, hosts that do not exist and TLS verification failures.
+// It never retries a Response.
+var RetryTransient RetryCheck = func(req *http.Request, res *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if req != nil && req.Context().Err() != nil {
+		return false
+	}
+
+	return !IsDNSNotFound(err) && !IsTLSVerification(err)
+}
+
+// Or returns a RetryCheck retrying when any of the checks does.
+func Or(checks ...RetryCheck) RetryCheck {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		for _, check := range checks {
+			if check(req, res, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// And returns a RetryCheck retrying when all the checks do.
+func And(checks ...RetryCheck) RetryCheck {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		for _, check := range checks {
+			if !check(req, res, err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Not returns a RetryCheck retrying when the check does not.
+func Not(check RetryCheck) RetryCheck {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		return !check(req, res, err)
+	}
+}
+
+// OnMethods is a RetryCheck retrying the requests made with any of the
+// methods. It is meant to be combined with other checks using And.
+func OnMethods(methods ...string) RetryCheck {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		if req == nil {
+			return false
+		}
+		for _, method := range methods {
+			if strings.EqualFold(method, req.Method) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnStatus is a RetryCheck retrying the Responses with any of the status codes.
+func OnStatus(codes ...int) RetryCheck {
+	retryOn := RetryOn(codes)
+	return func(req *http.Request, res *http.Response, err error) bool {
+		return res != nil && retryOn(res)
+	}
+}
+
+// OnErrors is a RetryCheck retrying the transport errors matching any of
+// the predicates, such as IsTimeout. Without predicates, it retries every
+// transport error.
+func OnErrors(preds ...func(error) bool) RetryCheck {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		if err == nil {
+			return false
+		}
+		if len(preds) == 0 {
+			return true
+		}
+		for _, pred := range preds {
+			if pred(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsTimeout reports whether the error is a network timeout.
+func IsTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionReset reports whether the error is caused by the connection
+// being reset or closed by the server.
+func IsConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsDNSNotFound reports whether the error is caused by a host that does not
+// exist (NXDOMAIN).
+func IsDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// IsTLSVerification reports whether the error is caused by the failed
+// verification of the certificate of the server or a server not speaking TLS.
+func IsTLSVerification(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		headerErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &headerErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
+
+// retries reports whether the failed attempt of the request can be retried.
+// Without a Check, Responses are retried according to the Policy and
+// transport errors according to RetryTransient.
+func (r *Retry) retries(req *http.Request, res *http.Response, err error) bool {
+	if r.Check != nil {
+		return r.Check(req, res, err)
+	}
+	if err != nil {
+		return RetryTransient(req, nil, err)
+	}
+
+	return r.Policy(res)
+}
@@ -1,7 +1,14 @@
 package jac
 
 import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"testing"
 )
 
@@ -78,3 +85,58 @@ func TestRetryIdempotentsOn(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryCheck(t *testing.T) {
+	get, _ := http.NewRequest("GET", "https://test.com", nil)
+	post, _ := http.NewRequest("POST", "https://test.com", nil)
+	cancelled, _ := http.NewRequest("GET", "https://test.com", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled = cancelled.WithContext(ctx)
+
+	timeout := &url.Error{Op: "Get", URL: "https://test.com", Err: &net.OpError{Op: "dial", Err: timeoutError{}}}
+	reset := &url.Error{Op: "Get", URL: "https://test.com", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}
+	nxdomain := &url.Error{Op: "Get", URL: "https://test.com", Err: &net.DNSError{Name: "test.com", IsNotFound: true}}
+	untrusted := &url.Error{Op: "Get", URL: "https://test.com", Err: x509.UnknownAuthorityError{}}
+	unavailable := &http.Response{StatusCode: 503}
+
+	idempotentTransient := Or(
+		And(OnMethods("GET", "PUT"), OnErrors(IsTimeout, IsConnectionReset)),
+		OnStatus(503),
+	)
+	tests := []struct {
+		name  string
+		check RetryCheck
+		req   *http.Request
+		res   *http.Response
+		err   error
+		want  bool
+	}{
+		{name: "transient timeout", check: RetryTransient, req: get, err: timeout, want: true},
+		{name: "transient reset", check: RetryTransient, req: get, err: reset, want: true},
+		{name: "transient nxdomain", check: RetryTransient, req: get, err: nxdomain, want: false},
+		{name: "transient tls", check: RetryTransient, req: get, err: untrusted, want: false},
+		{name: "transient cancelled", check: RetryTransient, req: cancelled, err: fmt.Errorf("dial: %w", context.Canceled), want: false},
+		{name: "transient response", check: RetryTransient, req: get, res: unavailable, want: false},
+		{name: "methods and errors", check: idempotentTransient, req: get, err: reset, want: true},
+		{name: "non-idempotent method", check: idempotentTransient, req: post, err: reset, want: false},
+		{name: "unmatched error", check: idempotentTransient, req: get, err: nxdomain, want: false},
+		{name: "status", check: idempotentTransient, req: post, res: unavailable, want: true},
+		{name: "unmatched status", check: idempotentTransient, req: get, res: &http.Response{StatusCode: 500}, want: false},
+		{name: "any error", check: OnErrors(), req: get, err: io.ErrUnexpectedEOF, want: true},
+		{name: "not", check: Not(OnErrors(IsDNSNotFound)), req: get, err: nxdomain, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(tt.req, tt.res, tt.err); got != tt.want {
+				t.Errorf("RetryCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
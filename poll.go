@@ -0,0 +1,145 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// defMaxPollDuration is the default maximum duration of polling an async request.
+const defMaxPollDuration = 1 * time.Hour
+
+// DefaultPollPolicy is the PollPolicy used by Clients without one.
+var DefaultPollPolicy = &PollPolicy{
+	Backoff:     ExponentialBackoff(defMinWait, defMaxWait),
+	MaxDuration: defMaxPollDuration,
+}
+
+// PollPolicy determines how async requests are polled until they are ready.
+type PollPolicy struct {
+	// Interval is the constant wait duration between polls.
+	// It is ignored when Backoff is set. Default is 1 second.
+	Interval time.Duration
+
+	// Backoff determines the wait duration before the next poll based on
+	// the amount of polls already made.
+	Backoff Backoff
+
+	// MaxPolls is the maximum amount of polls made for a single request.
+	// Zero means no limit.
+	MaxPolls int
+
+	// MaxDuration is the maximum total duration of polling a single request.
+	// Zero means no limit.
+	MaxDuration time.Duration
+
+	// Jitter randomizes every wait duration by up to the given fraction
+	// in both directions. It should be between 0 and 1.
+	Jitter float64
+
+	// MaxPollers is the maximum amount of requests polled concurrently
+	// by a single DoAsync call. Zero means no limit.
+	MaxPollers int
+}
+
+// PollTimeoutError is returned when an async request
+// is not ready within the limits of the PollPolicy.
+type PollTimeoutError struct {
+	Polls   int
+	Elapsed time.Duration
+}
+
+// Error implements the error interface.
+func (e *PollTimeoutError) Error() string {
+	return fmt.Sprintf("jac: async request not ready after %d polls in %s", e.Polls, e.Elapsed.Round(time.Millisecond))
+}
+
+// wait returns the wait duration after the given amount of polls.
+func (p *PollPolicy) wait(polls int) time.Duration {
+	var wait time.Duration
+	switch {
+	case p.Backoff != nil:
+		wait = p.Backoff(polls)
+	case p.Interval > 0:
+		wait = p.Interval
+	default:
+		wait = defBaseWait
+	}
+
+	if p.Jitter > 0 {
+		wait += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(wait))
+	}
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait
+}
+
+// poller keeps track of the polls of a single async request.
+type poller struct {
+	policy *PollPolicy
+	start  time.Time
+	polls  int
+
+	// index is the position of the request among the polled requests.
+	index int
+
+	// jobs is the JobStore the polling state is recorded in
+	// and job is the recorded job of the request, if any.
+	jobs JobStore
+	job  *AsyncJob
+
+	// finished reports whether the request has finished, so that its
+	// job no longer needs to be resumed: it was ready, IsReady failed
+	// or the limits of the policy were reached.
+	finished bool
+
+	// progress is called with the progress reported by the poll responses.
+	progress ProgressFunc
+}
+
+func newPoller(policy *PollPolicy) *poller {
+	return &poller{policy: policy, start: time.Now()}
+}
+
+// next waits before the next poll. A positive hint, such as the duration
+// of a Retry-After header, overrides the wait duration of the policy.
+//
+// It returns a PollTimeoutError when the limits of the policy are reached
+// and the error of the context if it is done before the wait is over.
+func (p *poller) next(ctx context.Context, hint time.Duration) error {
+	elapsed := time.Since(p.start)
+	if max := p.policy.MaxPolls; max > 0 && p.polls >= max {
+		return &PollTimeoutError{Polls: p.polls, Elapsed: elapsed}
+	}
+
+	wait := hint
+	if wait <= 0 {
+		wait = p.policy.wait(p.polls)
+	}
+	if max := p.policy.MaxDuration; max > 0 {
+		if elapsed >= max {
+			return &PollTimeoutError{Polls: p.polls, Elapsed: elapsed}
+		}
+		if remaining := max - elapsed; wait > remaining {
+			wait = remaining
+		}
+	}
+
+	return sleep(ctx, wait)
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,164 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPollPolicy_wait(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *PollPolicy
+		polls  int
+		min    time.Duration
+		max    time.Duration
+	}{
+		{
+			name:   "default interval",
+			policy: &PollPolicy{},
+			min:    defBaseWait,
+			max:    defBaseWait,
+		},
+		{
+			name:   "interval",
+			policy: &PollPolicy{Interval: time.Second * 5},
+			polls:  3,
+			min:    time.Second * 5,
+			max:    time.Second * 5,
+		},
+		{
+			name:   "backoff",
+			policy: &PollPolicy{Interval: time.Second, Backoff: LinearBackoff(time.Second)},
+			polls:  3,
+			min:    time.Second * 3,
+			max:    time.Second * 3,
+		},
+		{
+			name:   "jitter",
+			policy: &PollPolicy{Interval: time.Second * 10, Jitter: 0.5},
+			min:    time.Second * 5,
+			max:    time.Second * 15,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.policy.wait(tt.polls); got < tt.min || got > tt.max {
+					t.Fatalf("PollPolicy.wait() = %s, want between %s and %s", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func Test_poller_next(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		policy  *PollPolicy
+		polls   int
+		start   time.Time
+		timeout bool
+		wantErr error
+	}{
+		{
+			name:   "within limits",
+			ctx:    context.Background(),
+			policy: &PollPolicy{Interval: time.Millisecond, MaxPolls: 3, MaxDuration: time.Minute},
+			polls:  2,
+			start:  time.Now(),
+		},
+		{
+			name:    "max polls",
+			ctx:     context.Background(),
+			policy:  &PollPolicy{Interval: time.Millisecond, MaxPolls: 3},
+			polls:   3,
+			start:   time.Now(),
+			timeout: true,
+		},
+		{
+			name:    "max duration",
+			ctx:     context.Background(),
+			policy:  &PollPolicy{Interval: time.Millisecond, MaxDuration: time.Minute},
+			polls:   1,
+			start:   time.Now().Add(-time.Hour),
+			timeout: true,
+		},
+		{
+			name:    "canceled",
+			ctx:     canceled,
+			policy:  &PollPolicy{Interval: time.Hour},
+			start:   time.Now(),
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &poller{policy: tt.policy, polls: tt.polls, start: tt.start}
+			err := p.next(tt.ctx, 0)
+			var timeoutErr *PollTimeoutError
+			if errors.As(err, &timeoutErr) != tt.timeout {
+				t.Errorf("poller.next() error = %v, timeout %v", err, tt.timeout)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("poller.next() error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.timeout && tt.wantErr == nil && err != nil {
+				t.Errorf("poller.next() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+type neverReadyRequest struct {
+	*GetRequest
+}
+
+func (n *neverReadyRequest) Path() string {
+	return "/job"
+}
+
+func (n *neverReadyRequest) IsReady(*Response) (bool, error) {
+	return false, nil
+}
+
+func (n *neverReadyRequest) OnReady() Request {
+	return n
+}
+
+func TestClient_DoAsyncPollTimeout(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(202)
+	}))
+	defer svr.Close()
+
+	c := &Client{
+		BaseURL:           svr.URL,
+		DisableLogging:    true,
+		DisableCoalescing: true,
+		Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		Poll:              &PollPolicy{Interval: time.Millisecond, MaxPolls: 3},
+	}
+	res := <-c.DoAsync(context.Background(), &neverReadyRequest{})
+
+	var timeoutErr *PollTimeoutError
+	if !errors.As(res.Err, &timeoutErr) || timeoutErr.Polls != 3 {
+		t.Errorf("Client.DoAsync() error = %v, want PollTimeoutError after 3 polls", res.Err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.Poll = &PollPolicy{Interval: time.Hour}
+	start := time.Now()
+	res = <-c.DoAsync(ctx, &neverReadyRequest{})
+	if !errors.Is(res.Err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Client.DoAsync() error = %v after %s, want prompt cancellation", res.Err, time.Since(start))
+	}
+}
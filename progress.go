@@ -0,0 +1,81 @@
+package jac
+
+import "context"
+
+// Progress is the progress of an async request reported by a poll response.
+type Progress struct {
+	// Percent is the completed percentage of the operation between 0 and 100.
+	Percent float64
+
+	// Stage is the current stage of the operation, such as "queued".
+	Stage string
+
+	// Message is a human readable description of the progress.
+	Message string
+
+	// Request is the AsyncRequest the progress belongs to.
+	Request AsyncRequest
+
+	// Index is the position of the Request among the requests
+	// passed to DoAsyncProgress.
+	Index int
+
+	// Poll is the number of the poll that reported the progress.
+	Poll int
+}
+
+// AsyncProgress is implemented by AsyncRequests whose poll responses report
+// the progress of the operation.
+type AsyncProgress interface {
+	// Progress extracts the progress from a poll response.
+	// It returns false if the response does not report any progress.
+	Progress(*Response) (*Progress, bool)
+}
+
+// ProgressFunc is called with the progress reported by the poll responses.
+type ProgressFunc func(*Progress)
+
+// ProgressChan returns a ProgressFunc sending the progress on the channel.
+// Progress is dropped when the channel is not ready to receive it, so that
+// a slow consumer never blocks polling.
+func ProgressChan(ch chan<- *Progress) ProgressFunc {
+	return func(p *Progress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// DoAsyncProgress is like DoAsync but calls fn with the progress reported
+// by every poll response of the requests implementing AsyncProgress.
+//
+// fn is called from the polling goroutines and must be safe for concurrent
+// use when more than one request is polled.
+func (c *Client) DoAsyncProgress(ctx context.Context, fn ProgressFunc, asyncs ...AsyncRequest) chan *AsyncResponse {
+	c.once.Do(c.init)
+	ch := make(chan *AsyncResponse)
+	go c.doAsyncs(ch, ctx, asyncs, func(ctx context.Context, _ int, request AsyncRequest, p *poller) *AsyncResponse {
+		p.progress = fn
+		return c.poll(ctx, request, p)
+	})
+
+	return ch
+}
+
+// reportProgress reports the progress of the poll response, if any.
+func (p *poller) reportProgress(request AsyncRequest, res *Response) {
+	reporter, ok := request.(AsyncProgress)
+	if !ok || p.progress == nil {
+		return
+	}
+
+	progress, ok := reporter.Progress(res)
+	if !ok {
+		return
+	}
+	progress.Request = request
+	progress.Index = p.index
+	progress.Poll = p.polls
+	p.progress(progress)
+}
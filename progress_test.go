@@ -0,0 +1,103 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testProgressRequest struct {
+	*GetRequest
+}
+
+func (t *testProgressRequest) Path() string {
+	return "/progress"
+}
+
+func (t *testProgressRequest) IsReady(res *Response) (bool, error) {
+	return bytes.Contains(res.Data, []byte(`"percent":100`)), nil
+}
+
+func (t *testProgressRequest) OnReady() Request {
+	return &testResultRequest{id: "progress"}
+}
+
+func (t *testProgressRequest) Progress(res *Response) (*Progress, bool) {
+	var body struct {
+		Percent float64 `json:"percent"`
+		Stage   string  `json:"stage"`
+	}
+	if err := json.Unmarshal(res.Data, &body); err != nil {
+		return nil, false
+	}
+
+	return &Progress{Percent: body.Percent, Stage: body.Stage}, true
+}
+
+func TestClient_DoAsyncProgress(t *testing.T) {
+	var polls int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /progress", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&polls, 1)
+		fmt.Fprintf(w, `{"percent":%d,"stage":"stage %d"}`, n*50-50, n)
+	})
+	mux.HandleFunc("GET /results/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "result "+r.PathValue("id"))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	c := &Client{
+		BaseURL:           svr.URL,
+		DisableLogging:    true,
+		DisableCoalescing: true,
+		Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		Poll:              &PollPolicy{Interval: time.Millisecond, MaxPolls: 5},
+	}
+
+	progress := make(chan *Progress, 10)
+	got := <-c.DoAsyncProgress(context.Background(), ProgressChan(progress), &testProgressRequest{})
+	close(progress)
+	if got.Err != nil {
+		t.Fatalf("Client.DoAsyncProgress() error = %v", got.Err)
+	}
+	if string(got.Response.Data) != "result progress" {
+		t.Errorf("Client.DoAsyncProgress() = %s, want result progress", got.Response.Data)
+	}
+
+	want := []float64{0, 50, 100}
+	var i int
+	for p := range progress {
+		if i >= len(want) {
+			t.Fatalf("Client.DoAsyncProgress() reported more than %d progress events", len(want))
+		}
+		if p.Percent != want[i] || p.Poll != i+1 || p.Stage != fmt.Sprintf("stage %d", i+1) || p.Request != got.Request {
+			t.Errorf("Client.DoAsyncProgress() progress %d = %+v", i, p)
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Errorf("Client.DoAsyncProgress() reported %d progress events, want %d", i, len(want))
+	}
+}
+
+func TestProgressChan(t *testing.T) {
+	ch := make(chan *Progress, 1)
+	fn := ProgressChan(ch)
+	fn(&Progress{Percent: 1})
+	fn(&Progress{Percent: 2})
+	if p := <-ch; p.Percent != 1 {
+		t.Errorf("ProgressChan() sent %v, want 1", p.Percent)
+	}
+	select {
+	case p := <-ch:
+		t.Errorf("ProgressChan() sent %v to a full channel", p.Percent)
+	default:
+	}
+}
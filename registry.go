@@ -0,0 +1,178 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ClientRef is a handle on the Client registered with a name. It always
+// makes requests with the Client currently registered, so that the
+// configuration of a Client can be replaced at runtime without recreating
+// the callers holding the ClientRef.
+type ClientRef struct {
+	name   string
+	client atomic.Pointer[Client]
+}
+
+// Name returns the name the Client is registered with.
+func (r *ClientRef) Name() string {
+	return r.name
+}
+
+// Client returns the Client currently registered.
+func (r *ClientRef) Client() *Client {
+	return r.client.Load()
+}
+
+// Do makes the Request with the Client currently registered.
+func (r *ClientRef) Do(ctx context.Context, req Request) (*Response, error) {
+	return r.Client().Do(ctx, req)
+}
+
+// Get makes a GET request with the Client currently registered.
+func (r *ClientRef) Get(ctx context.Context, u string) (*Response, error) {
+	return r.Client().Get(ctx, u)
+}
+
+// Registry holds Clients by their Name.
+type Registry struct {
+	mu   sync.Mutex
+	refs map[string]*ClientRef
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{refs: map[string]*ClientRef{}}
+}
+
+// Register registers the Client with its Name and returns its ClientRef.
+//
+// When a Client is already registered with the name, it is replaced: the
+// existing ClientRefs make their next requests with the new Client, while
+// the in-flight requests of the old one complete. The old Client is closed
+// once they are done, except for the Cache and CacheStore it shares with
+// the registered Clients.
+func (r *Registry) Register(c *Client) (*ClientRef, error) {
+	if c.Name == "" {
+		return nil, errors.New("jac: cannot register a client without a name")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ref, ok := r.refs[c.Name]
+	if !ok {
+		ref = &ClientRef{name: c.Name}
+		r.refs[c.Name] = ref
+	}
+	if old := ref.client.Swap(c); old != nil && old != c {
+		shared := map[any]bool{}
+		for _, ref := range r.refs {
+			client := ref.Client()
+			client.once.Do(client.init)
+			for _, v := range []any{client.Cache, client.CacheStore} {
+				if v != nil {
+					markClosed(shared, v)
+				}
+			}
+		}
+		go func() {
+			<-old.requests.idle()
+			_ = old.close(shared)
+		}()
+	}
+
+	return ref, nil
+}
+
+// Lookup returns the ClientRef of the Client registered with the name
+// or nil if there is none.
+func (r *Registry) Lookup(name string) *ClientRef {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.refs[name]
+}
+
+// CloseAll closes every registered Client and empties the Registry.
+// A Cache or CacheStore shared by several Clients is closed once.
+func (r *Registry) CloseAll() error {
+	r.mu.Lock()
+	refs := r.refs
+	r.refs = map[string]*ClientRef{}
+	r.mu.Unlock()
+
+	var (
+		errs   []error
+		closed = map[any]bool{}
+	)
+	for name, ref := range refs {
+		if err := ref.Client().close(closed); err != nil {
+			errs = append(errs, fmt.Errorf("jac: closing client %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// inflight counts the in-flight requests of a Client.
+type inflight struct {
+	mu      sync.Mutex
+	n       int
+	waiters []chan struct{}
+}
+
+func (f *inflight) add() {
+	f.mu.Lock()
+	f.n++
+	f.mu.Unlock()
+}
+
+func (f *inflight) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.n--; f.n == 0 {
+		for _, w := range f.waiters {
+			close(w)
+		}
+		f.waiters = nil
+	}
+}
+
+// idle returns a channel closed once there are no in-flight requests.
+func (f *inflight) idle() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan struct{})
+	if f.n == 0 {
+		close(ch)
+	} else {
+		f.waiters = append(f.waiters, ch)
+	}
+
+	return ch
+}
+
+// DefaultRegistry is the Registry used by Register, Lookup and CloseAll.
+var DefaultRegistry = NewRegistry()
+
+// Register registers the Client with the DefaultRegistry.
+func Register(c *Client) (*ClientRef, error) {
+	return DefaultRegistry.Register(c)
+}
+
+// Lookup returns the ClientRef of the Client registered with the name
+// in the DefaultRegistry or nil if there is none.
+func Lookup(name string) *ClientRef {
+	return DefaultRegistry.Lookup(name)
+}
+
+// CloseAll closes every Client registered with the DefaultRegistry.
+func CloseAll() error {
+	return DefaultRegistry.CloseAll()
+}
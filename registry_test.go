@@ -0,0 +1,241 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Version")))
+	}))
+	defer srv.Close()
+
+	newClient := func(name, version string) *Client {
+		return &Client{
+			Name:              name,
+			BaseURL:           srv.URL,
+			Headers:           http.Header{"X-Version": {version}},
+			DisableLogging:    true,
+			DisableCoalescing: true,
+			Retry:             &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		clients []*Client
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "single",
+			clients: []*Client{newClient("stripe", "1")},
+			want:    "1",
+		},
+		{
+			name:    "hot swap",
+			clients: []*Client{newClient("stripe", "1"), newClient("stripe", "2")},
+			want:    "2",
+		},
+		{
+			name:    "no name",
+			clients: []*Client{newClient("", "1")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			var (
+				first *ClientRef
+				err   error
+			)
+			for i, c := range tt.clients {
+				ref, e := r.Register(c)
+				if i == 0 {
+					first = ref
+				}
+				err = e
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Registry.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := r.Lookup("stripe"); got != first {
+				t.Errorf("Registry.Lookup() = %p, want the first ClientRef %p", got, first)
+			}
+			res, err := first.Do(context.Background(), &testItemsRequest{GetRequest: &GetRequest{}, path: "/items"})
+			if err != nil {
+				t.Fatalf("ClientRef.Do() error = %v", err)
+			}
+			if got := string(res.Data); got != tt.want {
+				t.Errorf("ClientRef.Do() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_CloseAll(t *testing.T) {
+	r := NewRegistry()
+	cache := NewInMemoryCache()
+	if _, err := r.Register(&Client{Name: "a", BaseURL: "http://a.test", Cache: cache}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register(&Client{Name: "b", BaseURL: "http://b.test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.CloseAll(); err != nil {
+		t.Errorf("Registry.CloseAll() error = %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if got := r.Lookup(name); got != nil {
+			t.Errorf("Registry.Lookup(%s) = %v after CloseAll, want nil", name, got)
+		}
+	}
+}
+
+type closingCacheStore struct {
+	*MemoryCacheStore
+	closed atomic.Int32
+}
+
+func (c *closingCacheStore) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestRegistry_Register_sharedStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	store := &closingCacheStore{MemoryCacheStore: NewMemoryCacheStore()}
+	newClient := func() *Client {
+		return &Client{
+			Name:           "a",
+			BaseURL:        srv.URL,
+			CacheStore:     store,
+			DisableLogging: true,
+			Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		}
+	}
+
+	r := NewRegistry()
+	ref, err := r.Register(newClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register(newClient()); err != nil {
+		t.Fatal(err)
+	}
+	if n := store.closed.Load(); n != 0 {
+		t.Fatalf("Registry.Register() closed the shared store %d times, want 0", n)
+	}
+	if _, err := ref.Do(context.Background(), &testCacheGetRequest{}); err != nil {
+		t.Fatalf("ClientRef.Do() error = %v", err)
+	}
+
+	if err := r.CloseAll(); err != nil {
+		t.Fatalf("Registry.CloseAll() error = %v", err)
+	}
+	if n := store.closed.Load(); n != 1 {
+		t.Errorf("Registry.CloseAll() closed the shared store %d times, want 1", n)
+	}
+}
+
+func TestRegistry_Register_closesReplaced(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			received <- struct{}{}
+			<-release
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	newClient := func(store CacheStore) *Client {
+		return &Client{
+			Name:           "a",
+			BaseURL:        srv.URL,
+			CacheStore:     store,
+			DisableLogging: true,
+			Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+		}
+	}
+	eventually := func(cond func() bool) bool {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			if cond() {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := NewRegistry()
+	first := &closingCacheStore{MemoryCacheStore: NewMemoryCacheStore()}
+	ref, _ := r.Register(newClient(first))
+
+	// The first client is closed once its in-flight request is done.
+	done := make(chan error)
+	go func() {
+		_, err := ref.Get(context.Background(), "/slow")
+		done <- err
+	}()
+	<-received
+	stores := []*closingCacheStore{first}
+	for range 20 {
+		store := &closingCacheStore{MemoryCacheStore: NewMemoryCacheStore()}
+		stores = append(stores, store)
+		if _, err := r.Register(newClient(store)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(r.refs) != 1 {
+		t.Errorf("Registry clients = %d, want 1", len(r.refs))
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := first.closed.Load(); n != 0 {
+		t.Errorf("Registry.Register() closed the store of a client with an in-flight request")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("ClientRef.Get() error = %v", err)
+	}
+
+	last := len(stores) - 1
+	for i, store := range stores[:last] {
+		if !eventually(func() bool { return store.closed.Load() == 1 }) {
+			t.Errorf("replaced client %d store closed %d times, want 1", i, store.closed.Load())
+		}
+	}
+	if n := stores[last].closed.Load(); n != 0 {
+		t.Errorf("registered client store closed %d times, want 0", n)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	defer CloseAll()
+
+	ref, err := Register(&Client{Name: "default", BaseURL: "http://default.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Lookup("default"); got != ref {
+		t.Errorf("Lookup() = %p, want %p", got, ref)
+	}
+	if got := Lookup("missing"); got != nil {
+		t.Errorf("Lookup() = %v, want nil", got)
+	}
+}
@@ -5,15 +5,14 @@ import (
 	"context"
 	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/darrae/jac/internal/jachttp"
 )
 
+// CacheRequest is a CacheableRequest with a custom cache key.
 type CacheRequest interface {
-	Request
+	CacheableRequest
 	CacheKey() string
-	TTL() time.Duration
 	EvictionPolicy() func(cache Cache) bool
 }
 
@@ -0,0 +1,83 @@
+package jac
+
+import (
+	"context"
+	"sync"
+)
+
+// flightCall is an in-flight or completed call of a flightGroup.
+type flightCall struct {
+	done    chan struct{}
+	res     *Response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup coalesces concurrent calls sharing the same key
+// into a single execution whose result is shared by every caller.
+//
+// The shared call runs with a context detached from the callers'
+// cancellation. A caller whose context is done stops waiting without
+// affecting the others and the call itself is only canceled once
+// every caller has stopped waiting.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do executes and returns the results of fn, making sure that only one
+// execution is in-flight for a given key at a time.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (*Response, error)) (*Response, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go g.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.res, call.err
+	case <-ctx.Done():
+		g.detach(key, call)
+		return nil, ctx.Err()
+	}
+}
+
+// run executes fn and releases the waiters of the call.
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(context.Context) (*Response, error)) {
+	call.res, call.err = fn(ctx)
+
+	g.mu.Lock()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+
+	call.cancel()
+	close(call.done)
+}
+
+// detach removes a waiter from the call and cancels
+// the call if no other waiters are left.
+func (g *flightGroup) detach(key string, call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
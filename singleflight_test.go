@@ -0,0 +1,176 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_flightGroup_do(t *testing.T) {
+	var g flightGroup
+	var calls int64
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (*Response, error) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		return &Response{Data: []byte("shared")}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*Response, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = g.do(context.Background(), "key", fn)
+		}()
+	}
+	for waitersOf(&g, "key") != len(results) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("flightGroup.do() calls = %d, want 1", calls)
+	}
+	for _, res := range results {
+		if res != results[0] {
+			t.Errorf("flightGroup.do() = %p, want shared %p", res, results[0])
+		}
+	}
+	if len(g.calls) != 0 {
+		t.Errorf("flightGroup.do() leaked %d calls", len(g.calls))
+	}
+}
+
+func Test_flightGroup_detach(t *testing.T) {
+	var g flightGroup
+	release := make(chan struct{})
+	canceled := make(chan struct{})
+	fn := func(ctx context.Context) (*Response, error) {
+		select {
+		case <-release:
+			return &Response{}, nil
+		case <-ctx.Done():
+			close(canceled)
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		_, err := g.do(ctx, "key", fn)
+		leaderErr <- err
+	}()
+	for waitersOf(&g, "key") != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiterErr := make(chan error)
+	go func() {
+		_, err := g.do(context.Background(), "key", fn)
+		waiterErr <- err
+	}()
+	for waitersOf(&g, "key") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("flightGroup.do() leader error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if err := <-waiterErr; err != nil {
+		t.Errorf("flightGroup.do() waiter error = %v, want nil", err)
+	}
+
+	select {
+	case <-canceled:
+		t.Errorf("flightGroup.do() call canceled while a waiter remained")
+	default:
+	}
+}
+
+func Test_flightGroup_detachAll(t *testing.T) {
+	var g flightGroup
+	canceled := make(chan struct{})
+	fn := func(ctx context.Context) (*Response, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.do(ctx, "key", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("flightGroup.do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatalf("flightGroup.do() call was not canceled")
+	}
+	if waitersOf(&g, "key") != 0 {
+		t.Errorf("flightGroup.do() leaked call")
+	}
+}
+
+func waitersOf(g *flightGroup, key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if call, ok := g.calls[key]; ok {
+		return call.waiters
+	}
+	return 0
+}
+
+func TestClient_DoCoalescing(t *testing.T) {
+	var hits int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("success"))
+	}))
+	defer svr.Close()
+
+	tests := []struct {
+		name    string
+		disable bool
+		want    int64
+	}{
+		{name: "coalesced", want: 1},
+		{name: "disabled", disable: true, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt64(&hits, 0)
+			c := &Client{BaseURL: svr.URL, DisableLogging: true, DisableCoalescing: tt.disable}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					res, err := c.Do(context.Background(), &testGetRequest{id: "coalesce"})
+					if err != nil || string(res.Data) != "success" {
+						t.Errorf("Client.Do() = %v, %v", res, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := atomic.LoadInt64(&hits); got != tt.want {
+				t.Errorf("Client.Do() server hits = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
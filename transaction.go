@@ -2,7 +2,9 @@ package jac
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -13,6 +15,27 @@ import (
 
 var errRetriesExhausted = errors.New("Retry attempts exhausted")
 
+// StatusError is the error returned when a request receives
+// a Response that is not considered successful by the Client.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// newStatusError returns the StatusError of an unsuccessful Response.
+func newStatusError(res *Response) *StatusError {
+	return &StatusError{StatusCode: res.StatusCode, Header: res.Header, Body: res.Data}
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	if e.Body == nil {
+		return "Unexpected status code"
+	}
+	return "Unexpected status code, error body: " + string(e.Body)
+}
+
 type txnState int
 
 const (
@@ -56,6 +79,9 @@ type transaction struct {
 	response     *Response
 	hc           doer
 	isSuccessful func(*http.Response) bool
+	router       *endpointRouter
+	authorize    func(*http.Request) error
+	limit        func(context.Context) error
 }
 
 func (t *transaction) init() error {
@@ -84,9 +110,30 @@ func (t *transaction) attempt() txnState {
 		return txnUnrecoverable
 	case <-time.After(t.wait):
 	}
+	if t.limit != nil {
+		if err := t.limit(t.req.Context()); err != nil {
+			t.res, t.err = nil, err
+			return txnUnrecoverable
+		}
+	}
 
 	t.count++
+	var endpoint *Endpoint
+	if t.router != nil {
+		endpoint = t.router.route(t.req)
+	}
+	if endpoint != nil && t.authorize != nil {
+		// Authorizers may sign the URL, which changes with the endpoint.
+		if err := t.authorize(t.req); err != nil {
+			t.res, t.err = nil, err
+			return txnUnrecoverable
+		}
+	}
+	start := time.Now()
 	t.res, t.err = t.hc.Do(t.req)
+	if endpoint != nil && t.req.Context().Err() == nil {
+		endpoint.record(t.res, t.err, time.Since(start))
+	}
 
 	t.state = t.resolveState()
 
@@ -101,8 +148,11 @@ func (t *transaction) resolveState() txnState {
 }
 
 func (t *transaction) noResponse() txnState {
+	if !t.ret.retries(t.req, nil, t.err) {
+		return txnUnrecoverable
+	}
 	if t.ret.MaxAmount <= t.count {
-		t.err = errRetriesExhausted
+		t.err = fmt.Errorf("%w: %w", errRetriesExhausted, t.err)
 		return txnExhausted
 	}
 	return txnRetryable
@@ -112,15 +162,17 @@ func (t *transaction) receivedResponse() txnState {
 	if checkIfSuccessful(t.isSuccessful, t.res) {
 		return txnSuccessful
 	}
-	t.err = errors.New("Unexpected status code")
-	if t.res != nil && t.res.Body != nil {
+	statusErr := &StatusError{StatusCode: t.res.StatusCode, Header: t.res.Header}
+	if t.res.Body != nil {
 		if data, err := io.ReadAll(t.res.Body); err == nil {
-			t.err = errors.New("Unexpected status code, error body: " + string(data))
+			statusErr.Body = data
+			t.res.Body = io.NopCloser(bytes.NewReader(data))
 		}
 	}
-	if t.ret.Policy(t.res) {
+	t.err = statusErr
+	if t.ret.retries(t.req, t.res, nil) {
 		if t.ret.MaxAmount <= t.count {
-			t.err = errRetriesExhausted
+			t.err = fmt.Errorf("%w: %w", errRetriesExhausted, statusErr)
 			return txnExhausted
 		}
 		return txnRetryable
@@ -154,7 +206,10 @@ func (t *transaction) result() (*Response, error) {
 		}
 
 		t.response = &Response{
-			Data: data,
+			URL:        t.req.URL,
+			Data:       data,
+			Header:     t.res.Header,
+			StatusCode: t.res.StatusCode,
 		}
 		return t.response, t.err
 	}
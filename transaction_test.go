@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -176,6 +177,37 @@ func Test_transaction_resolveState(t *testing.T) {
 			},
 			want: txnUnrecoverable,
 		},
+		{
+			name: "permanent error",
+			fields: fields{
+				count: 1,
+				err:   &net.DNSError{Name: "test.com", IsNotFound: true},
+				ret: &Retry{
+					Policy:    nil,
+					Backoff:   nil,
+					MaxAmount: 5,
+				},
+			},
+			want: txnUnrecoverable,
+		},
+		{
+			name: "check ignores policy",
+			fields: fields{
+				count: 1,
+				err:   nil,
+				ret: &Retry{
+					Policy:    RetryOn(defRetryCodes),
+					Backoff:   nil,
+					MaxAmount: 5,
+					Check:     OnErrors(),
+				},
+				res: &http.Response{
+					StatusCode: 502,
+					Body:       io.NopCloser(strings.NewReader(`{"test": "testvalue", "amount": 10}"`)),
+				},
+			},
+			want: txnUnrecoverable,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(
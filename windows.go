@@ -0,0 +1,164 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// defMinWindowSize is the default size below which windows are not halved.
+const defMinWindowSize = time.Second
+
+// WindowRequestFunc returns the Request querying the [from, to) time range.
+type WindowRequestFunc func(from, to time.Time) Request
+
+// WindowOptions configures how DoWindows splits a time range into windows.
+type WindowOptions struct {
+	// Size is the size of the initial windows.
+	// Default is a single window covering the whole range.
+	Size time.Duration
+
+	// MinSize is the size below which windows are not halved.
+	// Default is 1 second.
+	MinSize time.Duration
+
+	// Concurrency is the maximum number of windows requested
+	// concurrently. Default is no limit.
+	Concurrency int
+
+	// TooMany reports whether the result of a window signals too many
+	// results, in which case the window is halved and its halves are
+	// requested instead. Default reports a 413 Request Entity Too Large.
+	TooMany func(*Response, error) bool
+}
+
+// WindowError is returned by DoWindows for windows that still have too
+// many results at the minimum size.
+type WindowError struct {
+	From, To time.Time
+
+	// Err is the error of the window request, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *WindowError) Error() string {
+	return "jac: too many results in the window from " + e.From.Format(time.RFC3339) + " to " + e.To.Format(time.RFC3339)
+}
+
+func (e *WindowError) Unwrap() error {
+	return e.Err
+}
+
+// DoWindows splits the [from, to) time range into windows of the Size of
+// the options and returns an iterator over the responses of the requests
+// made by fn for every window.
+//
+// Windows whose result signals too many results are halved until they reach
+// the MinSize. The windows are requested concurrently but the responses are
+// yielded in time order. A failed window yields the responses preceding the
+// failure followed by its error, and ends the iteration. Breaking out of
+// the loop cancels the requests in progress.
+func (c *Client) DoWindows(ctx context.Context, from, to time.Time, fn WindowRequestFunc, opts *WindowOptions) iter.Seq2[*Response, error] {
+	if opts == nil {
+		opts = &WindowOptions{}
+	}
+
+	return func(yield func(*Response, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		windows := opts.split(from, to)
+		type result struct {
+			responses []*Response
+			err       error
+			done      chan struct{}
+		}
+		results := make([]result, len(windows))
+		for i := range results {
+			results[i].done = make(chan struct{})
+		}
+
+		go forEach(ctx, len(windows), opts.Concurrency, false, func(ctx context.Context, i int) error {
+			defer close(results[i].done)
+			if err := ctx.Err(); err != nil {
+				results[i].err = err
+				return err
+			}
+			w := windows[i]
+			results[i].responses, results[i].err = c.doWindow(ctx, w[0], w[1], fn, opts)
+			return results[i].err
+		})
+
+		for i := range results {
+			<-results[i].done
+			for _, res := range results[i].responses {
+				if !yield(res, nil) {
+					return
+				}
+			}
+			if results[i].err != nil {
+				yield(nil, results[i].err)
+				return
+			}
+		}
+	}
+}
+
+// doWindow requests the window, halving it while it has too many results.
+func (c *Client) doWindow(ctx context.Context, from, to time.Time, fn WindowRequestFunc, opts *WindowOptions) ([]*Response, error) {
+	res, err := c.Do(ctx, fn(from, to))
+	if !opts.tooMany(res, err) {
+		if err != nil {
+			return nil, err
+		}
+		return []*Response{res}, nil
+	}
+
+	half := to.Sub(from) / 2
+	if half < opts.minSize() {
+		return nil, &WindowError{From: from, To: to, Err: err}
+	}
+	mid := from.Add(half)
+	first, err := c.doWindow(ctx, from, mid, fn, opts)
+	if err != nil {
+		return first, err
+	}
+	second, err := c.doWindow(ctx, mid, to, fn, opts)
+
+	return append(first, second...), err
+}
+
+// split returns the initial windows of the [from, to) range.
+func (o *WindowOptions) split(from, to time.Time) [][2]time.Time {
+	var windows [][2]time.Time
+	for start := from; start.Before(to); {
+		end := to
+		if o.Size > 0 && start.Add(o.Size).Before(to) {
+			end = start.Add(o.Size)
+		}
+		windows = append(windows, [2]time.Time{start, end})
+		start = end
+	}
+
+	return windows
+}
+
+func (o *WindowOptions) tooMany(res *Response, err error) bool {
+	if o.TooMany != nil {
+		return o.TooMany(res, err)
+	}
+
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusRequestEntityTooLarge
+}
+
+func (o *WindowOptions) minSize() time.Duration {
+	if o.MinSize <= 0 {
+		return defMinWindowSize
+	}
+
+	return o.MinSize
+}
@@ -0,0 +1,140 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testWindowRequest struct {
+	*GetRequest
+	from, to time.Time
+}
+
+func (t *testWindowRequest) Path() string {
+	return "/events"
+}
+
+func (t *testWindowRequest) Query() url.Values {
+	return url.Values{"from": {t.from.Format(time.RFC3339)}, "to": {t.to.Format(time.RFC3339)}}
+}
+
+func TestClient_DoWindows(t *testing.T) {
+	var requests int64
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+		to, _ := time.Parse(time.RFC3339, r.URL.Query().Get("to"))
+		if to.Sub(from) > 48*time.Hour || r.URL.Query().Get("from") == "2024-01-09T00:00:00Z" {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		fmt.Fprint(w, from.Format("Jan 2"))
+	}))
+	defer svr.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fn := func(from, to time.Time) Request {
+		return &testWindowRequest{from: from, to: to}
+	}
+	tests := []struct {
+		name      string
+		days      int
+		opts      *WindowOptions
+		stopAfter int
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "halved windows",
+			days: 8,
+			opts: &WindowOptions{Size: 96 * time.Hour, Concurrency: 2},
+			want: []string{"Jan 1", "Jan 3", "Jan 5", "Jan 7"},
+		},
+		{
+			name: "uneven last window",
+			days: 7,
+			opts: &WindowOptions{Size: 48 * time.Hour},
+			want: []string{"Jan 1", "Jan 3", "Jan 5", "Jan 7"},
+		},
+		{
+			name:    "too many at the minimum size",
+			days:    10,
+			opts:    &WindowOptions{Size: 48 * time.Hour, MinSize: 24 * time.Hour},
+			want:    []string{"Jan 1", "Jan 3", "Jan 5", "Jan 7"},
+			wantErr: true,
+		},
+		{
+			name:      "break",
+			days:      8,
+			opts:      &WindowOptions{Size: 24 * time.Hour, Concurrency: 1},
+			stopAfter: 2,
+			want:      []string{"Jan 1", "Jan 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				BaseURL:        svr.URL,
+				DisableLogging: true,
+				Retry:          &Retry{Policy: DefaultPolicy, Backoff: LinearBackoff(time.Millisecond), MaxAmount: 1},
+			}
+
+			var got []string
+			var err error
+			end := start.AddDate(0, 0, tt.days)
+			for res, e := range c.DoWindows(context.Background(), start, end, fn, tt.opts) {
+				if e != nil {
+					err = e
+					break
+				}
+				got = append(got, string(res.Data))
+				if len(got) == tt.stopAfter {
+					break
+				}
+			}
+
+			var windowErr *WindowError
+			if tt.wantErr != errors.As(err, &windowErr) {
+				t.Fatalf("Client.DoWindows() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Client.DoWindows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowOptions_split(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		size time.Duration
+		to   time.Time
+		want int
+	}{
+		{name: "single window", to: start.Add(72 * time.Hour), want: 1},
+		{name: "even", size: 24 * time.Hour, to: start.Add(72 * time.Hour), want: 3},
+		{name: "uneven", size: 48 * time.Hour, to: start.Add(72 * time.Hour), want: 2},
+		{name: "empty range", size: 24 * time.Hour, to: start, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&WindowOptions{Size: tt.size}).split(start, tt.to)
+			if len(got) != tt.want {
+				t.Fatalf("WindowOptions.split() = %d windows, want %d", len(got), tt.want)
+			}
+			for i, w := range got {
+				if i > 0 && !w[0].Equal(got[i-1][1]) || i == len(got)-1 && !w[1].Equal(tt.to) {
+					t.Errorf("WindowOptions.split() window %d = %v", i, w)
+				}
+			}
+		})
+	}
+}